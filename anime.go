@@ -19,14 +19,15 @@ type animeEndRes struct {
 	Response latestAnimeRespond `json:"response"`
 }
 
-type metaData struct {
+// MetaData echoes the paging parameters of a list response.
+type MetaData struct {
 	Limit   string `json:"_limit"`
 	Offset  string `json:"_offset"`
 	OrderBy string `json:"_order_by"`
 }
 
 type latestAnimeRespond struct {
-	MetaData metaData `json:"meta_data"`
+	MetaData MetaData `json:"meta_data"`
 	Data     []Anime  `json:"data"`
 }
 
@@ -48,185 +49,181 @@ type Anime struct {
 }
 
 func (s *AnimeService) GetLatestAnimes(offset, limit int) ([]Anime, error) {
-	payload := make(JsonPayload)
-
-	var err error
-	var payloadStr string
+	return s.GetLatestAnimesWithContext(context.Background(), offset, limit)
+}
 
-	err = payload.WithOffset(offset)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithLimit(limit)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithOrder(LatestFirst)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithListType(LatestUpdatedEpisodeNew)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithJustInfo("Yes")
+func (s *AnimeService) GetLatestAnimesWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	payload, err := latestAnimesPayload(offset, limit)
 	if err != nil {
 		return []Anime{}, err
 	}
+	return s.getAnimeList(ctx, payload)
+}
 
-	payloadStr, err = payload.ToJson()
-	if err != nil {
-		return []Anime{}, err
-	}
-	return s.getAnimeList(payloadStr)
+// LatestAnimesPager walks the latest updated animes limit at a time.
+func (s *AnimeService) LatestAnimesPager(limit int) *Pager[Anime] {
+	return s.pager(limit, latestAnimesPayload)
+}
+
+func latestAnimesPayload(offset, limit int) (JsonPayload, error) {
+	return listPayload(LatestUpdatedEpisodeNew, LatestFirst, offset, limit)
 }
 
 func (s *AnimeService) SearchByName(offset, limit int, animeName string, orderBy order) ([]Anime, error) {
-	payload := make(JsonPayload)
-	var err error
-	var payloadStr string
+	return s.SearchByNameWithContext(context.Background(), offset, limit, animeName, orderBy)
+}
 
-	err = payload.WithOffset(offset)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithLimit(limit)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithOrder(orderBy)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithListType(Filter)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithJustInfo("Yes")
+func (s *AnimeService) SearchByNameWithContext(ctx context.Context, offset, limit int, animeName string, orderBy order) ([]Anime, error) {
+	payload, err := filterPayload(offset, limit, orderBy)
 	if err != nil {
 		return []Anime{}, err
 	}
 	payload.WithName(animeName)
+	return s.getAnimeList(ctx, payload)
+}
 
-	payloadStr, err = payload.ToJson()
-	if err != nil {
-		return []Anime{}, err
-	}
-	return s.getAnimeList(payloadStr)
+func (s *AnimeService) SearchByNamePager(limit int, animeName string, orderBy order) *Pager[Anime] {
+	return s.pager(limit, func(offset, limit int) (JsonPayload, error) {
+		payload, err := filterPayload(offset, limit, orderBy)
+		if err != nil {
+			return nil, err
+		}
+		payload.WithName(animeName)
+		return payload, nil
+	})
 }
 
 func (s *AnimeService) OrderBy(offset, limit int, orderBy order) ([]Anime, error) {
-	payload := make(JsonPayload)
-	var err error
-	var payloadStr string
+	return s.OrderByWithContext(context.Background(), offset, limit, orderBy)
+}
 
-	err = payload.WithOffset(offset)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithLimit(limit)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithOrder(orderBy)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithListType(Filter)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithJustInfo("Yes")
-	if err != nil {
-		return []Anime{}, err
-	}
-	payload.WithName("")
+func (s *AnimeService) OrderByWithContext(ctx context.Context, offset, limit int, orderBy order) ([]Anime, error) {
+	return s.SearchByNameWithContext(ctx, offset, limit, "", orderBy)
+}
 
-	payloadStr, err = payload.ToJson()
-	if err != nil {
-		return []Anime{}, err
-	}
-	return s.getAnimeList(payloadStr)
+func (s *AnimeService) OrderByPager(limit int, orderBy order) *Pager[Anime] {
+	return s.SearchByNamePager(limit, "", orderBy)
 }
 
 func (s *AnimeService) GetAnimeListBySeason(offset, limit int, season season, orderBy order, releaseYear int) ([]Anime, error) {
-	payload := make(JsonPayload)
-	var err error
-	var payloadStr string
+	return s.GetAnimeListBySeasonWithContext(context.Background(), offset, limit, season, orderBy, releaseYear)
+}
 
-	err = payload.WithOffset(offset)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithLimit(limit)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithOrder(orderBy)
-	if err != nil {
-		return []Anime{}, err
-	}
-	err = payload.WithListType(Filter)
+func (s *AnimeService) GetAnimeListBySeasonWithContext(ctx context.Context, offset, limit int, season season, orderBy order, releaseYear int) ([]Anime, error) {
+	payload, err := seasonPayload(offset, limit, season, orderBy, releaseYear)
 	if err != nil {
 		return []Anime{}, err
 	}
-	err = payload.WithJustInfo("Yes")
+	return s.getAnimeList(ctx, payload)
+}
+
+func (s *AnimeService) AnimeListBySeasonPager(limit int, season season, orderBy order, releaseYear int) *Pager[Anime] {
+	return s.pager(limit, func(offset, limit int) (JsonPayload, error) {
+		return seasonPayload(offset, limit, season, orderBy, releaseYear)
+	})
+}
+
+func seasonPayload(offset, limit int, season season, orderBy order, releaseYear int) (JsonPayload, error) {
+	payload, err := filterPayload(offset, limit, orderBy)
 	if err != nil {
-		return []Anime{}, err
+		return nil, err
 	}
 	payload.WithName("")
 
 	err = payload.WithSeason(season)
 	if err != nil {
-		return []Anime{}, err
+		return nil, err
 	}
 	err = payload.WithReleaseYear(releaseYear)
 	if err != nil {
-		return []Anime{}, err
+		return nil, err
 	}
+	return payload, nil
+}
 
-	payloadStr, err = payload.ToJson()
-	if err != nil {
-		return []Anime{}, err
-	}
-	return s.getAnimeList(payloadStr)
+func filterPayload(offset, limit int, orderBy order) (JsonPayload, error) {
+	return listPayload(Filter, orderBy, offset, limit)
 }
 
 func (s *AnimeService) CustomAnimePayload(payload JsonPayload) ([]Anime, error) {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return []Anime{}, err
-	}
-	return s.getAnimeList(string(data))
+	return s.CustomAnimePayloadWithContext(context.Background(), payload)
 }
 
-func (s *AnimeService) getAnime(params url.Values, path, method string) (*http.Response, error) {
-	return s.getAnimeWithContext(context.Background(), params, path, method)
+func (s *AnimeService) CustomAnimePayloadWithContext(ctx context.Context, payload JsonPayload) ([]Anime, error) {
+	return s.getAnimeList(ctx, payload)
 }
 
-func (s *AnimeService) getAnimeWithContext(ctx context.Context, params url.Values, path, method string) (*http.Response, error) {
-	u, _ := url.Parse(BaseAPI)
-	u.Path = path
+// CustomAnimePayloadPager walks payload limit at a time,
+// its own offset and limit are ignored.
+func (s *AnimeService) CustomAnimePayloadPager(payload JsonPayload, limit int) *Pager[Anime] {
+	return s.pager(limit, func(offset, limit int) (JsonPayload, error) {
+		p := make(JsonPayload, len(payload)+2)
+		for k, v := range payload {
+			p[k] = v
+		}
+		if err := p.WithOffset(offset); err != nil {
+			return nil, err
+		}
+		if err := p.WithLimit(limit); err != nil {
+			return nil, err
+		}
+		return p, nil
+	})
+}
 
-	u.RawQuery = params.Encode()
+func (s *AnimeService) pager(limit int, payload func(offset, limit int) (JsonPayload, error)) *Pager[Anime] {
+	return newPager(limit, func(ctx context.Context, offset, limit int) ([]Anime, MetaData, int, error) {
+		p, err := payload(offset, limit)
+		if err != nil {
+			return nil, MetaData{}, 0, err
+		}
+		res, err := s.getAnimePage(ctx, p)
+		return res.Data, res.MetaData, len(res.Data), err
+	})
+}
+
+func (s *AnimeService) getAnimeWithContext(ctx context.Context, params url.Values, path, method string) (*http.Response, error) {
+	u, err := s.client.url(path, params)
+	if err != nil {
+		return nil, err
+	}
 
 	var res *http.Response
-	res, err := s.client.request(ctx, method, u.String(), nil)
+	res, err = s.client.request(ctx, method, u, nil)
 	return res, err
 }
 
-func (s *AnimeService) getAnimeList(query string) ([]Anime, error) {
-	params := url.Values{}
-	params.Set("json", query)
-	res, err := s.getAnime(params, PublishedAnimesPath, http.MethodGet)
+func (s *AnimeService) getAnimeList(ctx context.Context, payload JsonPayload) ([]Anime, error) {
+	res, err := s.getAnimePage(ctx, payload)
 	if err != nil {
 		return []Anime{}, err
 	}
+	return res.Data, nil
+}
 
+func (s *AnimeService) getAnimePage(ctx context.Context, payload JsonPayload) (latestAnimeRespond, error) {
 	var animes animeEndRes
-	err = json.NewDecoder(res.Body).Decode(&animes)
+	err := s.getPublished(ctx, payload, &animes)
+	return animes.Response, err
+}
+
+// getPublished decodes the published animes endpoint response for payload into v.
+func (s *AnimeService) getPublished(ctx context.Context, payload JsonPayload, v interface{}) error {
+	query, err := payload.ToJson()
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("json", query)
+	res, err := s.getAnimeWithContext(ctx, params, PublishedAnimesPath, http.MethodGet)
+	if err != nil {
+		return err
+	}
+
+	err = json.NewDecoder(res.Body).Decode(v)
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(res.Body)
-	return animes.Response.Data, err
+	return err
 }
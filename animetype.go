@@ -0,0 +1,22 @@
+package tohru
+
+import "fmt"
+
+const (
+	TV      animeType = "TV"
+	Movie   animeType = "Movie"
+	OVA     animeType = "OVA"
+	ONA     animeType = "ONA"
+	Special animeType = "Special"
+)
+
+type animeType string
+
+func (t animeType) valid() error {
+	switch t {
+	case TV, Movie, OVA, ONA, Special:
+		return nil
+	default:
+		return fmt.Errorf("invalid anime type, Please use predefined anime types by package")
+	}
+}
@@ -0,0 +1,250 @@
+package tohru
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry is a cached response body with its validators.
+type CacheEntry struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Expires      time.Time `json:"expires"`
+}
+
+func (e CacheEntry) fresh() bool {
+	return time.Now().Before(e.Expires)
+}
+
+// Cache stores API responses keyed by method and URL, clients of different
+// mirrors can share it.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+	Delete(key string)
+}
+
+// CacheTTL returns how long a GET response of path may be served from the cache,
+// a non positive duration disables caching for the request.
+type CacheTTL func(path string, query url.Values) time.Duration
+
+// DefaultCacheTTL keeps anime details for a day, the latest episodes list
+// for a minute and every other anime list for ten minutes.
+func DefaultCacheTTL(path string, query url.Values) time.Duration {
+	switch strings.TrimPrefix(path, "/") {
+	case GetAnimeDetailsPath:
+		return 24 * time.Hour
+	case PublishedAnimesPath:
+		var payload JsonPayload
+		if err := json.Unmarshal([]byte(query.Get("json")), &payload); err == nil &&
+			payload["list_type"] == string(LatestUpdatedEpisodeNew) {
+			return time.Minute
+		}
+		return 10 * time.Minute
+	default:
+		return 0
+	}
+}
+
+func WithCache(cache Cache, ttl CacheTTL) Option {
+	return func(t *TohruClient) {
+		if ttl == nil {
+			ttl = DefaultCacheTTL
+		}
+		t.cache = cache
+		t.cacheTTL = ttl
+	}
+}
+
+func (c *TohruClient) cachedRequest(ctx context.Context, rawURL string) (*http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	path := c.endpointPath(rawURL)
+	ttl := c.cacheTTL(path, u.Query())
+	if ttl <= 0 {
+		return c.send(ctx, http.MethodGet, rawURL, nil, nil)
+	}
+
+	key := http.MethodGet + " " + u.Scheme + "://" + u.Host + u.Path + "?" + u.Query().Encode()
+	entry, ok := c.cache.Get(key)
+	if ok && entry.fresh() {
+		return entry.response(), nil
+	}
+
+	var header http.Header
+	if ok && (entry.ETag != "" || entry.LastModified != "") {
+		header = http.Header{}
+		if entry.ETag != "" {
+			header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	res, err := c.send(ctx, http.MethodGet, rawURL, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		entry.Expires = time.Now().Add(ttl)
+		c.cache.Set(key, entry)
+		return entry.response(), nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	entry = CacheEntry{
+		Body:         body,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		Expires:      time.Now().Add(ttl),
+	}
+	c.cache.Set(key, entry)
+	return entry.response(), nil
+}
+
+func (e CacheEntry) response() *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+	}
+}
+
+// LRUCache is an in memory Cache holding at most size entries.
+type LRUCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry CacheEntry
+}
+
+func NewLRUCache(size int) *LRUCache {
+	if size < 1 {
+		size = 1
+	}
+	return &LRUCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (l *LRUCache) Get(key string) (CacheEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	l.order.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+func (l *LRUCache) Set(key string, entry CacheEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.entries[key]; ok {
+		el.Value.(*lruItem).entry = entry
+		l.order.MoveToFront(el)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruItem{key, entry})
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruItem).key)
+	}
+}
+
+func (l *LRUCache) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.entries[key]; ok {
+		l.order.Remove(el)
+		delete(l.entries, key)
+	}
+}
+
+// FileCache is a Cache storing one JSON file per entry inside a directory.
+type FileCache struct {
+	dir string
+}
+
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (f *FileCache) Get(key string) (CacheEntry, bool) {
+	data, err := os.ReadFile(f.path(key))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (f *FileCache) Set(key string, entry CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(f.dir, "entry-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), f.path(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+func (f *FileCache) Delete(key string) {
+	_ = os.Remove(f.path(key))
+}
@@ -0,0 +1,143 @@
+package tohru
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", CacheEntry{Body: []byte("a")})
+	c.Set("b", CacheEntry{Body: []byte("b")})
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a evicted too early")
+	}
+	c.Set("c", CacheEntry{Body: []byte("c")})
+	if _, ok := c.Get("b"); ok {
+		t.Error("b kept, want it evicted as the least recently used")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("%s evicted", key)
+		}
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("a kept after Delete")
+	}
+}
+
+// cacheServer answers anime details with an ETag and counts the requests
+// and the 304 answers.
+type cacheServer struct {
+	*httptest.Server
+	calls, notModified atomic.Int32
+}
+
+func newCacheServer(t *testing.T, name string) *cacheServer {
+	t.Helper()
+	cs := &cacheServer{}
+	cs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cs.calls.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			cs.notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"response":{"anime_id":"1","anime_name":"` + name + `"}}`))
+	}))
+	t.Cleanup(cs.Close)
+	return cs
+}
+
+func TestCacheServesFreshEntries(t *testing.T) {
+	srv := newCacheServer(t, "Tohru")
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL), WithCache(NewLRUCache(8), nil))
+	for i := 0; i < 3; i++ {
+		if _, err := c.AnimeService.GetAnimeDetails(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := srv.calls.Load(); got != 1 {
+		t.Errorf("%d requests, want 1", got)
+	}
+}
+
+func TestCacheRevalidatesExpiredEntries(t *testing.T) {
+	srv := newCacheServer(t, "Tohru")
+	ttl := func(string, url.Values) time.Duration { return time.Nanosecond }
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL), WithCache(NewLRUCache(8), ttl))
+	for i := 0; i < 2; i++ {
+		anime, err := c.AnimeService.GetAnimeDetails(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if anime.AnimeName != "Tohru" {
+			t.Errorf("request %d: got anime %q, want the cached one", i, anime.AnimeName)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := srv.calls.Load(); got != 2 {
+		t.Errorf("%d requests, want 2", got)
+	}
+	if got := srv.notModified.Load(); got != 1 {
+		t.Errorf("%d conditional requests answered 304, want 1", got)
+	}
+}
+
+func TestCacheTTLDisablesCaching(t *testing.T) {
+	srv := newCacheServer(t, "Tohru")
+	ttl := func(string, url.Values) time.Duration { return 0 }
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL), WithCache(NewLRUCache(8), ttl))
+	for i := 0; i < 2; i++ {
+		if _, err := c.AnimeService.GetAnimeDetails(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := srv.calls.Load(); got != 2 {
+		t.Errorf("%d requests, want 2", got)
+	}
+}
+
+func TestFileCacheKeepsMirrorsApart(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Kanna", "Elma"} {
+		srv := newCacheServer(t, name)
+		c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL), WithCache(cache, nil))
+		anime, err := c.AnimeService.GetAnimeDetails(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if anime.AnimeName != name {
+			t.Errorf("got anime %q from the %s mirror", anime.AnimeName, name)
+		}
+	}
+}
+
+func TestDefaultCacheTTL(t *testing.T) {
+	latest, _ := latestAnimesPayload(0, 10)
+	query, _ := latest.ToJson()
+	tests := []struct {
+		path  string
+		query url.Values
+		want  time.Duration
+	}{
+		{"/" + GetAnimeDetailsPath, nil, 24 * time.Hour},
+		{"/" + PublishedAnimesPath, url.Values{"json": {query}}, time.Minute},
+		{"/" + PublishedAnimesPath, url.Values{"json": {`{"list_type":"filter"}`}}, 10 * time.Minute},
+		{GetEpisodePath, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := DefaultCacheTTL(tt.path, tt.query); got != tt.want {
+			t.Errorf("DefaultCacheTTL(%s, %v) = %v, want %v", tt.path, tt.query, got, tt.want)
+		}
+	}
+}
@@ -1,15 +1,19 @@
 package tohru
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 const (
-	BaseAPI = "https://anslayer.com"
+	BaseAPI        = "https://anslayer.com"
+	DefaultTimeout = 30 * time.Second
 )
 
 type errorRes struct {
@@ -21,18 +25,44 @@ type service struct {
 	client *TohruClient
 }
 
+// TohruClient is safe for concurrent use by multiple goroutines,
+// its configuration must not be changed once NewTohruClient returns.
 type TohruClient struct {
 	cfg     *Config
 	client  *http.Client
 	header  http.Header
+	baseURL string
+	retry   RetryPolicy
 	service service
+	// httpOptions run on client once every Option ran
+	httpOptions []func(*http.Client)
+
+	// fileHost talks to file hosts without the API middlewares
+	fileHost *http.Client
+
+	limiter          *RateLimiter
+	endpointLimiters map[string]*RateLimiter
+
+	cache    Cache
+	cacheTTL CacheTTL
+
+	middlewares []Middleware
+	logger      *slog.Logger
+	metrics     MetricsCollector
+	resolvers   *ResolverRegistry
+	genres      *genreCatalog
+
+	decodeConcurrency int
+	decodeTimeout     time.Duration
+	ranking           ranking
+	probe             bool
 
 	AnimeService   *AnimeService
 	EpisodeService *EpisodeService
 }
 
-func NewTohruClient(cfg *Config) *TohruClient {
-	client := http.Client{}
+func NewTohruClient(cfg *Config, opts ...Option) *TohruClient {
+	client := http.Client{Timeout: DefaultTimeout}
 
 	header := http.Header{}
 	header.Set("Content-Type", "application/json")
@@ -41,9 +71,29 @@ func NewTohruClient(cfg *Config) *TohruClient {
 	header.Set("Client-Secret", cfg.clientSecret)
 
 	tohru := &TohruClient{
-		client: &client,
-		header: header,
-		cfg:    cfg,
+		client:    &client,
+		header:    header,
+		baseURL:   BaseAPI,
+		logger:    slog.New(discardHandler{}),
+		metrics:   nopMetrics{},
+		resolvers: NewResolverRegistry(&KobayashiResolver{}),
+		genres:    newGenreCatalog(),
+
+		decodeConcurrency: DefaultDecodeConcurrency,
+		decodeTimeout:     DefaultDecodeTimeout,
+		cfg:               cfg,
+	}
+
+	for _, opt := range opts {
+		opt(tohru)
+	}
+	for _, opt := range tohru.httpOptions {
+		opt(tohru.client)
+	}
+	fileHost := *tohru.client
+	tohru.fileHost = &fileHost
+	if len(tohru.middlewares) > 0 {
+		tohru.client.Transport = chain(tohru.client.Transport, tohru.middlewares)
 	}
 
 	tohru.service.client = tohru
@@ -54,32 +104,150 @@ func NewTohruClient(cfg *Config) *TohruClient {
 	return tohru
 }
 
-func (c *TohruClient) request(ctx context.Context, method, url string, body io.Reader) (*http.Response, error) {
+func (c *TohruClient) url(path string, params url.Values) (string, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", err
+	}
+	u = u.JoinPath(path)
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// endpointPath returns the path of rawURL without the base URL path,
+// e.g. /anime/public/anime/get-anime-details for a mirror at https://host/mirror.
+func (c *TohruClient) endpointPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	path := u.Path
+	if base, err := url.Parse(c.baseURL); err == nil {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+	}
+	return "/" + strings.TrimPrefix(path, "/")
+}
+
+// newFileHostRequest builds a request carrying the User-Agent but none of
+// the Anslayer credentials, which must never be sent to file hosts.
+func (c *TohruClient) newFileHostRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
+	if ua := c.header.Get("User-Agent"); ua != "" {
+		req.Header.Set("User-Agent", ua)
+	}
+	return req, nil
+}
+
+func (c *TohruClient) request(ctx context.Context, method, url string, body io.Reader) (*http.Response, error) {
+	if c.cache != nil && method == http.MethodGet && body == nil {
+		return c.cachedRequest(ctx, url)
+	}
+	return c.send(ctx, method, url, body, nil)
+}
 
+// send performs the request applying rate limits and retries,
+// header is added to the request headers.
+func (c *TohruClient) send(ctx context.Context, method, url string, body io.Reader, header http.Header) (*http.Response, error) {
+	var payload []byte
 	if body != nil {
-		c.header.Set("Content-Type", "application/x-www-form-urlencoded")
+		var err error
+		payload, err = io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	path := c.endpointPath(url)
+	for attempt := 1; ; attempt++ {
+		if err := c.waitRateLimit(ctx, path); err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		res, err := c.do(ctx, method, url, payload, body != nil, header)
+		c.metrics.ObserveRequest(path, time.Since(start), err)
+		if err == nil {
+			c.logger.DebugContext(ctx, "request completed",
+				slog.String("method", method),
+				slog.String("path", path),
+				slog.Int("attempt", attempt),
+				slog.Int("status", res.StatusCode),
+				slog.Duration("duration", time.Since(start)))
+			return res, nil
+		}
+
+		retry := c.retry.shouldRetry(attempt, method, res, err)
+		info := RetryAttempt{
+			Attempt: attempt,
+			Method:  method,
+			Path:    path,
+			Err:     err,
+			Retry:   retry,
+		}
+		if res != nil {
+			info.StatusCode = res.StatusCode
+		}
+		if retry {
+			info.Wait = c.retry.backoff(attempt, res)
+		}
+		if c.retry.OnAttempt != nil {
+			c.retry.OnAttempt(info)
+		}
+		if !retry {
+			c.logger.Log(ctx, failureLevel(err), "request failed",
+				slog.String("method", method),
+				slog.String("path", path),
+				slog.Int("attempt", attempt),
+				slog.Any("error", err))
+			return nil, err
+		}
+		c.logger.WarnContext(ctx, "retrying request",
+			slog.String("method", method),
+			slog.String("path", path),
+			slog.Int("attempt", attempt),
+			slog.Duration("wait", info.Wait),
+			slog.Any("error", err))
+		if err := sleep(ctx, info.Wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// do sends a single request, on a non 200 response the body is consumed
+// and the response is returned alongside an *APIError. A 304 is only
+// accepted for conditional requests.
+func (c *TohruClient) do(ctx context.Context, method, url string, payload []byte, hasBody bool, header http.Header) (*http.Response, error) {
+	var body io.Reader
+	if hasBody {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = c.header.Clone()
+	if hasBody {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for k, v := range header {
+		req.Header[k] = v
 	}
-	req.Header = c.header
 
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
-	} else if resp.StatusCode != 200 {
-		_, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		var errRes errorRes
-		err = json.NewDecoder(resp.Body).Decode(&errRes)
+	} else if resp.StatusCode == http.StatusNotModified && header != nil {
+		return resp, nil
+	} else if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
 		if err != nil {
 			return nil, err
 		}
-		defer resp.Body.Close()
-		return nil, fmt.Errorf("%s : %s", errRes.Title, errRes.Detail)
+		return resp, newAPIError(resp, body)
 	}
 	return resp, nil
 }
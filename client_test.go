@@ -0,0 +1,103 @@
+package tohru
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithBaseURLKeepsPath(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"response":{"anime_id":"1"}}`))
+	}))
+	defer srv.Close()
+
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL+"/mirror"))
+	if _, err := c.AnimeService.GetAnimeDetails(1); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "/mirror/" + GetAnimeDetailsPath
+	if len(paths) != 1 || paths[0] != want {
+		t.Fatalf("got paths %v, want [%s]", paths, want)
+	}
+}
+
+func TestConcurrentRequestsKeepHeadersApart(t *testing.T) {
+	var leaked atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+			leaked.Add(1)
+		}
+		if r.Method == http.MethodPost && r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Errorf("POST sent Content-Type %q", r.Header.Get("Content-Type"))
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, GetEpisodePath):
+			_, _ = w.Write([]byte(`{"response":{"data":[{"episode_id":"1"}],"count":1}}`))
+		case strings.HasSuffix(r.URL.Path, PublishedAnimesPath):
+			_, _ = w.Write([]byte(`{"response":{"data":[{"anime_id":"1"}]}}`))
+		default:
+			_, _ = w.Write([]byte(`{"response":{"anime_id":"1"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if _, err := c.AnimeService.GetLatestAnimes(0, 10); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := c.AnimeService.GetAnimeDetails(1); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := c.EpisodeService.GetEpisodesList(1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := leaked.Load(); n > 0 {
+		t.Fatalf("%d GET requests carried the form Content-Type", n)
+	}
+}
+
+func TestHTTPClientOptionsIgnoreOrder(t *testing.T) {
+	transport := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, http.ErrNotSupported
+	})
+	tests := map[string][]Option{
+		"client first": {WithHTTPClient(&http.Client{}), WithTimeout(time.Second), WithTransport(transport)},
+		"client last":  {WithTimeout(time.Second), WithTransport(transport), WithHTTPClient(&http.Client{})},
+	}
+	for name, opts := range tests {
+		c := NewTohruClient(NewConfig("id", "secret", ""), opts...)
+		if c.client.Timeout != time.Second {
+			t.Errorf("%s: timeout %v, want 1s", name, c.client.Timeout)
+		}
+		if _, ok := c.fileHost.Transport.(RoundTripperFunc); !ok {
+			t.Errorf("%s: transport %T, want the WithTransport one", name, c.fileHost.Transport)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package tohru
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -80,8 +82,8 @@ type AnimeDetails struct {
 	AnimeUpdatedAtFormat string               `json:"anime_updated_at_format"`
 	AnimeCreatedAtFormat string               `json:"anime_created_at_format"`
 	MoreInfoResult       moreInfoResult       `json:"more_info_result"`
-	RelatedAnimes        interface{}          `json:"related_animes"`
-	RelatedNews          interface{}          `json:"related_news"`
+	RelatedAnimes        RelatedAnimes        `json:"related_animes"`
+	RelatedNews          RelatedNews          `json:"related_news"`
 	CommentFlagReasons   []commentFlagReasons `json:"comment_flag_reasons"`
 	ContentRating        []contentRating      `json:"content_rating"`
 	Role                 string               `json:"role"`
@@ -92,33 +94,68 @@ type RelatedAnimes struct {
 }
 
 // UnmarshalJSON Implements a custom marsheler
-// Because API have bad design and need to check edge cases
-func (ad *AnimeDetails) UnmarshalJSON(b []byte) error {
-	type details AnimeDetails
-	var a details
-	if err := json.Unmarshal(b, &a); err != nil {
+// Because API have bad design and return an empty array, null or false
+// instead of an object when there is no related anime
+func (ra *RelatedAnimes) UnmarshalJSON(b []byte) error {
+	if emptyJSON(b) {
+		*ra = RelatedAnimes{}
+		return nil
+	}
+	var animes []Anime
+	if err := json.Unmarshal(b, &animes); err == nil {
+		*ra = RelatedAnimes{Animes: animes}
+		return nil
+	}
+	type related RelatedAnimes
+	var r related
+	if err := json.Unmarshal(b, &r); err != nil {
 		return err
 	}
-	switch a.RelatedAnimes.(type) {
-	case []interface{}:
-		a.RelatedAnimes = RelatedAnimes{}
+	*ra = RelatedAnimes(r)
+	return nil
+}
+
+// UnmarshalJSON handles the same shapes as RelatedAnimes.UnmarshalJSON
+func (rn *RelatedNews) UnmarshalJSON(b []byte) error {
+	if emptyJSON(b) {
+		*rn = RelatedNews{}
+		return nil
+	}
+	var news []News
+	if err := json.Unmarshal(b, &news); err == nil {
+		*rn = RelatedNews{Data: news}
+		return nil
 	}
-	switch a.RelatedNews.(type) {
-	case []interface{}:
-		a.RelatedNews = RelatedNews{}
+	type related RelatedNews
+	var r related
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
 	}
-	*ad = AnimeDetails(a)
+	*rn = RelatedNews(r)
 	return nil
 }
 
+func emptyJSON(b []byte) bool {
+	switch string(bytes.TrimSpace(b)) {
+	case "", "null", "false", "[]", "{}", `""`:
+		return true
+	default:
+		return false
+	}
+}
+
 func (s *AnimeService) GetAnimeDetails(animeID int) (AnimeDetails, error) {
+	return s.GetAnimeDetailsWithContext(context.Background(), animeID)
+}
+
+func (s *AnimeService) GetAnimeDetailsWithContext(ctx context.Context, animeID int) (AnimeDetails, error) {
 	params := url.Values{}
 	id := strconv.Itoa(animeID)
 	params.Set("anime_id", id)
 	params.Set("fetch_episodes", "No")
 	params.Set("more_info", "Yes")
 
-	res, err := s.getAnime(params, GetAnimeDetailsPath, http.MethodGet)
+	res, err := s.getAnimeWithContext(ctx, params, GetAnimeDetailsPath, http.MethodGet)
 	if err != nil {
 		return AnimeDetails{}, err
 	}
@@ -129,5 +166,8 @@ func (s *AnimeService) GetAnimeDetails(animeID int) (AnimeDetails, error) {
 		_ = Body.Close()
 	}(res.Body)
 
+	if err == nil {
+		s.client.genres.register(details.Response.Genres()...)
+	}
 	return details.Response, err
 }
@@ -0,0 +1,59 @@
+package tohru
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRelatedUnmarshalShapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		animes  int
+		news    int
+		wantErr bool
+	}{
+		{"null", `null`, 0, 0, false},
+		{"false", `false`, 0, 0, false},
+		{"empty array", `[]`, 0, 0, false},
+		{"empty object", `{}`, 0, 0, false},
+		{"empty string", `""`, 0, 0, false},
+		{"array", `[{"anime_id":"1","news_id":"1"},{"anime_id":"2","news_id":"2"}]`, 2, 2, false},
+		{"object", `{"data":[{"anime_id":"1","news_id":"1"}]}`, 1, 1, false},
+		{"number", `42`, 0, 0, true},
+	}
+	for _, tt := range tests {
+		var details struct {
+			RelatedAnimes RelatedAnimes `json:"related_animes"`
+			RelatedNews   RelatedNews   `json:"related_news"`
+		}
+		err := json.Unmarshal([]byte(`{"related_animes":`+tt.json+`,"related_news":`+tt.json+`}`), &details)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := len(details.RelatedAnimes.Animes); got != tt.animes {
+			t.Errorf("%s: %d related animes, want %d", tt.name, got, tt.animes)
+		}
+		if got := len(details.RelatedNews.Data); got != tt.news {
+			t.Errorf("%s: %d related news, want %d", tt.name, got, tt.news)
+		}
+	}
+}
+
+func TestAnimeDetailsToleratesEmptyRelations(t *testing.T) {
+	var res animeDetailsEndRes
+	err := json.Unmarshal([]byte(`{"response":{"anime_id":"1","related_animes":false,"related_news":[]}}`), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Response.AnimeID != "1" {
+		t.Errorf("anime_id = %q, want 1", res.Response.AnimeID)
+	}
+}
@@ -0,0 +1,323 @@
+package tohru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrDownloadFailed = errors.New("every download link failed")
+
+// Progress reports the state of a running download.
+type Progress struct {
+	Link       DownloadInfo
+	Downloaded int64
+	// Total is -1 when the server did not send the file size.
+	Total int64
+	Done  bool
+}
+
+// Downloader streams episodes to disk. Data is written to dest+".part"
+// and renamed once complete, an existing part file is resumed with
+// an HTTP Range request. A Downloader may run several downloads at once,
+// its fields must not be changed once the first download started.
+type Downloader struct {
+	s *EpisodeService
+
+	// OnProgress is called every time a chunk is written and once the file is complete.
+	OnProgress func(Progress)
+	// Retries is the number of times a link is resumed after a transfer error
+	// or a 5xx response before moving to the next link, a 4xx response moves on
+	// at once.
+	Retries int
+	// Segments is the number of parallel ranged requests used when the host
+	// advertises Accept-Ranges. The progress of every segment is kept in
+	// dest+".state" so the download can be resumed.
+	Segments int
+	// BandwidthLimit caps, in bytes per second, the throughput shared by
+	// every download and segment of the Downloader. Zero means no limit.
+	BandwidthLimit int64
+
+	limiterOnce sync.Once
+	limiter     *RateLimiter
+}
+
+// NewDownloader returns a Downloader using the client HTTP settings.
+func (s *EpisodeService) NewDownloader() *Downloader {
+	return &Downloader{s: s, Retries: 2}
+}
+
+// Download downloads info to dest.
+func (d *Downloader) Download(ctx context.Context, info DownloadInfo, dest string) error {
+	return d.DownloadAny(ctx, DownloadInfos{info}, dest)
+}
+
+// DownloadEpisode resolves the direct links of the episode and downloads
+// the first one that succeeds to dest.
+func (d *Downloader) DownloadEpisode(ctx context.Context, animeName string, episodeNb int, dest string) error {
+	infos, err := d.s.GetDirectDownloadInfosWithContext(ctx, animeName, episodeNb)
+	if err != nil {
+		return err
+	}
+	return d.DownloadAny(ctx, infos, dest)
+}
+
+// DownloadAny tries infos in order, a link failing mid transfer is resumed
+// by the next one unless it serves a file of another size.
+func (d *Downloader) DownloadAny(ctx context.Context, infos DownloadInfos, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	part := dest + ".part"
+	statePath := dest + ".state"
+
+	d.limiterOnce.Do(func() {
+		if d.BandwidthLimit > 0 {
+			d.limiter = NewRateLimiter(float64(d.BandwidthLimit), 64<<10)
+		}
+	})
+
+	size := int64(-1)
+	var errs []error
+	for _, info := range infos {
+		for attempt := 0; attempt <= d.Retries; attempt++ {
+			var err error
+			if rangeSize, ok := d.rangeSize(ctx, info); ok {
+				size = rangeSize
+				err = d.fetchSegments(ctx, info, part, statePath, rangeSize)
+			} else {
+				if err := discardSegments(part, statePath); err != nil {
+					return err
+				}
+				err = d.fetch(ctx, info, part, &size)
+			}
+			if err == nil {
+				_ = os.Remove(statePath)
+				return os.Rename(part, dest)
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", info.Host, err))
+			d.s.client.logger.WarnContext(ctx, "download failed",
+				slog.String("host", info.Host),
+				slog.Int("attempt", attempt+1),
+				slog.Any("error", err))
+			var status statusError
+			if errors.As(err, &status) && status < 500 {
+				// the link itself is broken, retrying it will not help
+				break
+			}
+		}
+	}
+	return errors.Join(append([]error{ErrDownloadFailed}, errs...)...)
+}
+
+// discardSegments drops a part file preallocated by a segmented download,
+// its holes would be taken for downloaded data by a single stream.
+func discardSegments(part, statePath string) error {
+	if _, err := os.Stat(statePath); err != nil {
+		return nil
+	}
+	if err := os.Remove(part); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return os.Remove(statePath)
+}
+
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", int(e), http.StatusText(int(e)))
+}
+
+var errSizeChanged = errors.New("file size differs from the partial download, restarting")
+
+// fetch appends the missing bytes of info to part, size is the file size
+// seen so far or -1.
+func (d *Downloader) fetch(ctx context.Context, info DownloadInfo, part string, size *int64) error {
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	var byteRange string
+	if offset > 0 {
+		byteRange = "bytes=" + strconv.FormatInt(offset, 10) + "-"
+	}
+	res, err := d.get(ctx, info.EpisodeDirectDownloadLink, byteRange)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	total := res.ContentLength
+	switch {
+	case res.StatusCode == http.StatusPartialContent:
+		total = contentRangeTotal(res.Header.Get("Content-Range"))
+	case res.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		total = contentRangeTotal(res.Header.Get("Content-Range"))
+		if total == offset {
+			// the part file is already complete
+			d.progress(Progress{Link: info, Downloaded: offset, Total: offset, Done: true})
+			return nil
+		}
+		// the part file is longer than the remote file, start over
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		*size = total
+		return d.fetch(ctx, info, part, size)
+	case res.StatusCode == http.StatusOK:
+		// the server ignored the range, start over
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		offset = 0
+	default:
+		return statusError(res.StatusCode)
+	}
+
+	if total >= 0 {
+		if *size >= 0 && total != *size && offset > 0 {
+			*size = total
+			if err := f.Truncate(0); err != nil {
+				return err
+			}
+			return errSizeChanged
+		}
+		*size = total
+	}
+
+	report := d.throttledProgress()
+	w := &progressWriter{w: f, downloaded: offset, report: func(n int64) {
+		report(Progress{Link: info, Downloaded: n, Total: total})
+	}}
+	body := readerFunc(func(b []byte) (int, error) {
+		return d.read(ctx, res.Body, b)
+	})
+	if _, err := io.Copy(w, body); err != nil {
+		return err
+	}
+	if total >= 0 && w.downloaded != total {
+		return fmt.Errorf("short transfer, got %d of %d bytes", w.downloaded, total)
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	d.progress(Progress{Link: info, Downloaded: w.downloaded, Total: total, Done: true})
+	return nil
+}
+
+// get sends a GET request for link to a file host, byteRange is the Range
+// header value or empty.
+func (d *Downloader) get(ctx context.Context, link, byteRange string) (*http.Response, error) {
+	req, err := d.s.client.newFileHostRequest(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+
+	// the client timeout would cut long transfers, rely on ctx instead
+	client := *d.s.client.fileHost
+	client.Timeout = 0
+	return client.Do(req)
+}
+
+// read reads from r into b then waits for the bandwidth limiter.
+func (d *Downloader) read(ctx context.Context, r io.Reader, b []byte) (int, error) {
+	n, err := r.Read(b)
+	if n > 0 && d.limiter != nil {
+		if werr := d.limiter.WaitN(ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// rangeSize returns the size of the file served by info when it can be
+// downloaded in segments.
+func (d *Downloader) rangeSize(ctx context.Context, info DownloadInfo) (int64, bool) {
+	if d.Segments <= 1 {
+		return 0, false
+	}
+	if info.Probe == nil {
+		var err error
+		info, err = d.s.ProbeDownloadInfoWithContext(ctx, info)
+		if err != nil {
+			return 0, false
+		}
+	}
+	if !info.Probe.AcceptRanges || info.Probe.ContentLength <= 0 {
+		return 0, false
+	}
+	return info.Probe.ContentLength, true
+}
+
+func (d *Downloader) progress(p Progress) {
+	if d.OnProgress != nil {
+		d.OnProgress(p)
+	}
+}
+
+// throttledProgress returns a function reporting progress at most every 100ms,
+// chunks are usually a few KiB. Each download gets its own so concurrent
+// downloads do not drop each other's updates.
+func (d *Downloader) throttledProgress() func(Progress) {
+	var (
+		mu         sync.Mutex
+		lastReport time.Time
+	)
+	return func(p Progress) {
+		if d.OnProgress == nil {
+			return
+		}
+		mu.Lock()
+		now := time.Now()
+		if now.Sub(lastReport) < 100*time.Millisecond {
+			mu.Unlock()
+			return
+		}
+		lastReport = now
+		mu.Unlock()
+		d.OnProgress(p)
+	}
+}
+
+type progressWriter struct {
+	w          io.Writer
+	downloaded int64
+	report     func(int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.downloaded += int64(n)
+	p.report(p.downloaded)
+	return n, err
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(b []byte) (int, error) {
+	return f(b)
+}
@@ -0,0 +1,183 @@
+package tohru
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadRestartsStalePartFile(t *testing.T) {
+	content := []byte("0123456789")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "episode.mp4", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "episode.mp4")
+	stale := bytes.Repeat([]byte("x"), 46)
+	if err := os.WriteFile(dest+".part", stale, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewTohruClient(NewConfig("id", "secret", "")).EpisodeService.NewDownloader()
+	info := DownloadInfo{EpisodeDirectDownloadLink: srv.URL + "/episode.mp4", Host: "127.0.0.1"}
+	if err := d.Download(context.Background(), info, dest); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("got %q, want %q", got, content)
+	}
+}
+
+func TestDownloadKeepsCompletePartFile(t *testing.T) {
+	content := []byte("0123456789")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") == "" {
+			t.Error("complete part file downloaded again")
+		}
+		http.ServeContent(w, r, "episode.mp4", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "episode.mp4")
+	if err := os.WriteFile(dest+".part", content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewTohruClient(NewConfig("id", "secret", "")).EpisodeService.NewDownloader()
+	info := DownloadInfo{EpisodeDirectDownloadLink: srv.URL + "/episode.mp4", Host: "127.0.0.1"}
+	if err := d.Download(context.Background(), info, dest); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("got %q, want %q", got, content)
+	}
+}
+
+func TestFileHostTransfersSkipMiddlewares(t *testing.T) {
+	content := []byte("0123456789")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Client-Secret") != "" {
+			t.Error("credentials sent to the file host")
+		}
+		http.ServeContent(w, r, "episode.mp4", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	var seen atomic.Int32
+	c := NewTohruClient(NewConfig("id", "secret", ""),
+		WithMiddleware(Observe(func(*http.Request, *http.Response, time.Duration, error) {
+			seen.Add(1)
+		})))
+	info := DownloadInfo{EpisodeDirectDownloadLink: srv.URL + "/episode.mp4", Host: "127.0.0.1"}
+
+	if _, err := c.EpisodeService.ProbeDownloadInfo(info); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(t.TempDir(), "episode.mp4")
+	if err := c.EpisodeService.NewDownloader().Download(context.Background(), info, dest); err != nil {
+		t.Fatal(err)
+	}
+	if n := seen.Load(); n != 0 {
+		t.Errorf("API middlewares saw %d file host requests", n)
+	}
+}
+
+func TestConcurrentDownloadsReportProgress(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "20")
+		_, _ = w.Write([]byte("0123456789"))
+		w.(http.Flusher).Flush()
+		<-release
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer srv.Close()
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	defer unblock()
+
+	started := map[string]chan struct{}{"/a": make(chan struct{}), "/b": make(chan struct{})}
+	var once [2]sync.Once
+	d := NewTohruClient(NewConfig("id", "secret", "")).EpisodeService.NewDownloader()
+	d.OnProgress = func(p Progress) {
+		if p.Done || p.Downloaded != 10 {
+			return
+		}
+		path := strings.TrimPrefix(p.Link.EpisodeDirectDownloadLink, srv.URL)
+		once[path[1]-'a'].Do(func() { close(started[path]) })
+	}
+
+	dir := t.TempDir()
+	errs := make(chan error, 2)
+	for _, path := range []string{"/a", "/b"} {
+		info := DownloadInfo{EpisodeDirectDownloadLink: srv.URL + path, Host: "127.0.0.1"}
+		go func() {
+			errs <- d.Download(context.Background(), info, filepath.Join(dir, path))
+		}()
+		select {
+		case <-started[path]:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no progress reported for %s", path)
+		}
+	}
+	unblock()
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDownloadRetriesServerErrors(t *testing.T) {
+	content := []byte("0123456789")
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.mp4" {
+			calls.Add(1)
+			http.NotFound(w, r)
+			return
+		}
+		if calls.Add(1) == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		http.ServeContent(w, r, "episode.mp4", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	d := NewTohruClient(NewConfig("id", "secret", "")).EpisodeService.NewDownloader()
+	missing := DownloadInfo{EpisodeDirectDownloadLink: srv.URL + "/missing.mp4", Host: "127.0.0.1"}
+	flaky := DownloadInfo{EpisodeDirectDownloadLink: srv.URL + "/episode.mp4", Host: "127.0.0.1"}
+	dest := filepath.Join(t.TempDir(), "episode.mp4")
+	if err := d.DownloadAny(context.Background(), DownloadInfos{missing, flaky}, dest); err != nil {
+		t.Fatal(err)
+	}
+	// one request for the 404 link, then the 503 and its retry
+	if n := calls.Load(); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("got %q, want %q", got, content)
+	}
+}
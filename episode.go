@@ -4,15 +4,17 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	rncryptor "github.com/RNCryptor/RNCryptor-go"
-	"github.com/khatibomar/kobayashi"
 )
 
 const (
@@ -22,7 +24,10 @@ const (
 )
 
 var (
-	ErrBackupLink = fmt.Errorf("error while getting backup links")
+	ErrBackupLink        = fmt.Errorf("error while getting backup links")
+	ErrNoNextEpisode     = fmt.Errorf("episode has no next episode")
+	ErrNoPreviousEpisode = fmt.Errorf("episode has no previous episode")
+	ErrEpisodeNotFound   = fmt.Errorf("episode not found")
 )
 
 type EpisodeService service
@@ -38,7 +43,8 @@ type episodeUrls struct {
 	EpisodeURL        string `json:"episode_url"`
 }
 
-type nextEpisode struct {
+// EpisodeRef points to an adjacent episode.
+type EpisodeRef struct {
 	EpisodeID   string `json:"episode_id"`
 	EpisodeName string `json:"episode_name"`
 }
@@ -56,8 +62,8 @@ type Episode struct {
 	EpisodeAlreadyRatedByUser interface{}   `json:"episode_already_rated_by_user"`
 	EpisodeRatingByUser       interface{}   `json:"episode_rating_by_user"`
 	EpisodeUrls               []episodeUrls `json:"episode_urls"`
-	NextEpisode               []nextEpisode `json:"next_episode"`
-	PreviousEpisode           []interface{} `json:"previous_episode"`
+	NextEpisode               episodeRefs   `json:"next_episode"`
+	PreviousEpisode           looseList     `json:"previous_episode"`
 }
 
 type episodesResponse struct {
@@ -68,65 +74,99 @@ type episodesResponse struct {
 type DownloadInfo struct {
 	EpisodeHostLink           string
 	EpisodeDirectDownloadLink string
+	// Host is the host of EpisodeHostLink.
+	Host string
+	// Label is the quality label, e.g. 720p, when known.
+	Label string
+	// Index is the position of the link in the API response.
+	Index           int
+	ResolveDuration time.Duration
+	// Probe is set once the link was probed, see ProbeDownloadInfo.
+	Probe *ProbeResult
 }
 
 type DownloadLinks []string
 
 type DownloadInfos []DownloadInfo
 
-func (s *EpisodeService) getEpisode(params url.Values, path, method, payload string) (*http.Response, error) {
-	return s.getEpisodeWithContext(context.Background(), params, path, method, payload)
-}
-
 func (s *EpisodeService) getEpisodeWithContext(ctx context.Context, params url.Values, path, method, payload string) (*http.Response, error) {
-	u, _ := url.Parse(BaseAPI)
-	u.Path = path
-
-	u.RawQuery = params.Encode()
+	u, err := s.client.url(path, params)
+	if err != nil {
+		return nil, err
+	}
 
 	payloadReader := strings.NewReader(payload)
 
 	var res *http.Response
-	res, err := s.client.request(ctx, method, u.String(), payloadReader)
+	res, err = s.client.request(ctx, method, u, payloadReader)
 	return res, err
 }
 
 func (s *EpisodeService) GetEpisodesList(animeID int) ([]Episode, error) {
-	params := url.Values{}
+	return s.GetEpisodesListWithContext(context.Background(), animeID)
+}
+
+func (s *EpisodeService) GetEpisodesListWithContext(ctx context.Context, animeID int) ([]Episode, error) {
+	res, err := s.getEpisodesList(ctx, animeID)
+	if err != nil {
+		return []Episode{}, err
+	}
+	return res.Episodes, nil
+}
+
+// CountEpisodes returns the number of episodes of the anime reported by the server.
+func (s *EpisodeService) CountEpisodes(animeID int) (int, error) {
+	return s.CountEpisodesWithContext(context.Background(), animeID)
+}
+
+func (s *EpisodeService) CountEpisodesWithContext(ctx context.Context, animeID int) (int, error) {
 	payload := make(JsonPayload)
 	var err error
-	var payloadStr string
 
 	err = payload.WithJustInfo("No")
 	if err != nil {
-		return []Episode{}, err
+		return 0, err
 	}
 	err = payload.WithAnimeId(animeID)
 	if err != nil {
-		return []Episode{}, err
+		return 0, err
 	}
-	payloadStr, err = payload.ToJson()
+	// only count is needed, do not download every episode
+	err = payload.WithLimit(1)
 	if err != nil {
-		return []Episode{}, err
+		return 0, err
 	}
-	res, err := s.getEpisode(params, GetEpisodePath, http.MethodPost, "json="+payloadStr)
+	res, err := s.getEpisodes(ctx, payload)
 	if err != nil {
-		return []Episode{}, err
+		return 0, err
 	}
+	return res.Count, nil
+}
 
-	var episodes episodeEndRes
-	err = json.NewDecoder(res.Body).Decode(&episodes)
-	defer func(Body io.ReadCloser) {
-		_ = Body.Close()
-	}(res.Body)
-	return episodes.Response.Episodes, err
+func (s *EpisodeService) getEpisodesList(ctx context.Context, animeID int) (episodesResponse, error) {
+	payload := make(JsonPayload)
+	var err error
+
+	err = payload.WithJustInfo("No")
+	if err != nil {
+		return episodesResponse{}, err
+	}
+	err = payload.WithAnimeId(animeID)
+	if err != nil {
+		return episodesResponse{}, err
+	}
+	return s.getEpisodes(ctx, payload)
 }
 
 func (s *EpisodeService) GetEpisodeDetails(animeID, episodeID int) (Episode, error) {
-	params := url.Values{}
+	return s.GetEpisodeDetailsWithContext(context.Background(), animeID, episodeID)
+}
+
+// GetEpisodeDetailsWithContext returns ErrEpisodeNotFound when the server
+// does not return the requested episode.
+func (s *EpisodeService) GetEpisodeDetailsWithContext(ctx context.Context, animeID, episodeID int) (Episode, error) {
 	payload := make(JsonPayload)
 	var err error
-	var payloadStr string
 
 	err = payload.WithEpisodeId(episodeID)
 	if err != nil {
@@ -136,13 +176,31 @@ func (s *EpisodeService) GetEpisodeDetails(animeID, episodeID int) (Episode, err
 	if err != nil {
 		return Episode{}, err
 	}
-	payloadStr, err = payload.ToJson()
+	res, err := s.getEpisodes(ctx, payload)
+	if errors.Is(err, ErrNotFound) {
+		return Episode{}, fmt.Errorf("%w: anime %d episode %d: %w", ErrEpisodeNotFound, animeID, episodeID, err)
+	}
 	if err != nil {
 		return Episode{}, err
 	}
-	res, err := s.getEpisode(params, GetEpisodePath, http.MethodPost, "json="+payloadStr)
+
+	id := strconv.Itoa(episodeID)
+	for _, ep := range res.Episodes {
+		if ep.EpisodeID == id {
+			return ep, nil
+		}
+	}
+	return Episode{}, fmt.Errorf("%w: anime %d episode %d", ErrEpisodeNotFound, animeID, episodeID)
+}
+
+func (s *EpisodeService) getEpisodes(ctx context.Context, payload JsonPayload) (episodesResponse, error) {
+	payloadStr, err := payload.ToJson()
 	if err != nil {
-		return Episode{}, err
+		return episodesResponse{}, err
+	}
+	res, err := s.getEpisodeWithContext(ctx, url.Values{}, GetEpisodePath, http.MethodPost, "json="+payloadStr)
+	if err != nil {
+		return episodesResponse{}, err
 	}
 
 	var episodes episodeEndRes
@@ -150,15 +208,19 @@ func (s *EpisodeService) GetEpisodeDetails(animeID, episodeID int) (Episode, err
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(res.Body)
-	return episodes.Response.Episodes[0], err
+	return episodes.Response, err
 }
 
 func (s *EpisodeService) GetDownloadLinks(animeName string, episodeNb int) (DownloadLinks, error) {
+	return s.GetDownloadLinksWithContext(context.Background(), animeName, episodeNb)
+}
+
+func (s *EpisodeService) GetDownloadLinksWithContext(ctx context.Context, animeName string, episodeNb int) (DownloadLinks, error) {
 	params := url.Values{}
 
 	var err error
 
-	res, err := s.getEpisode(params, EpisodeDownloadPath, http.MethodPost, constructN(animeName, episodeNb))
+	res, err := s.getEpisodeWithContext(ctx, params, EpisodeDownloadPath, http.MethodPost, constructN(animeName, episodeNb))
 	if err != nil {
 		return DownloadLinks{}, err
 	}
@@ -172,11 +234,19 @@ func (s *EpisodeService) GetDownloadLinks(animeName string, episodeNb int) (Down
 }
 
 func (s *EpisodeService) GetDirectDownloadInfos(animeName string, episodeNb int) (DownloadInfos, error) {
-	return s.GetDirectDownloadInfosWithMax(animeName, episodeNb, -1)
+	return s.GetDirectDownloadInfosWithContext(context.Background(), animeName, episodeNb)
+}
+
+func (s *EpisodeService) GetDirectDownloadInfosWithContext(ctx context.Context, animeName string, episodeNb int) (DownloadInfos, error) {
+	return s.GetDirectDownloadInfosWithMaxWithContext(ctx, animeName, episodeNb, -1)
 }
 
 func (s *EpisodeService) GetFirstDirectDownloadInfo(animeName string, episodeNb int) (DownloadInfo, error) {
-	link, err := s.GetDirectDownloadInfosWithMax(animeName, episodeNb, 1)
+	return s.GetFirstDirectDownloadInfoWithContext(context.Background(), animeName, episodeNb)
+}
+
+func (s *EpisodeService) GetFirstDirectDownloadInfoWithContext(ctx context.Context, animeName string, episodeNb int) (DownloadInfo, error) {
+	link, err := s.GetDirectDownloadInfosWithMaxWithContext(ctx, animeName, episodeNb, 1)
 	if err != nil {
 		return DownloadInfo{}, err
 	}
@@ -189,10 +259,14 @@ type BackupLinks []struct {
 }
 
 func (s *EpisodeService) GetDirectDownloadInfosWithMax(animeName string, episodeNb int, maxNbOfLinks int) (DownloadInfos, error) {
+	return s.GetDirectDownloadInfosWithMaxWithContext(context.Background(), animeName, episodeNb, maxNbOfLinks)
+}
+
+func (s *EpisodeService) GetDirectDownloadInfosWithMaxWithContext(ctx context.Context, animeName string, episodeNb int, maxNbOfLinks int) (DownloadInfos, error) {
 	params := url.Values{}
 	var err error
 
-	res, err := s.getEpisode(params, EpisodeDownloadPath, http.MethodPost, constructN(animeName, episodeNb))
+	res, err := s.getEpisodeWithContext(ctx, params, EpisodeDownloadPath, http.MethodPost, constructN(animeName, episodeNb))
 	if err != nil {
 		return DownloadInfos{}, err
 	}
@@ -206,67 +280,58 @@ func (s *EpisodeService) GetDirectDownloadInfosWithMax(animeName string, episode
 		_ = Body.Close()
 	}(res.Body)
 
-	d := kobayashi.Decoder{}
-
 	if len(dwnLinks) < maxNbOfLinks || maxNbOfLinks <= 0 {
 		maxNbOfLinks = len(dwnLinks)
 	}
 
-	linksChan := make(chan DownloadInfo)
-	var wg sync.WaitGroup
-
-	for i := 0; i < len(dwnLinks); i++ {
-		wg.Add(1)
-		go func(link string) {
-			url, _ := d.Decode(link)
-			linksChan <- DownloadInfo{link, url}
-			wg.Done()
-		}(dwnLinks[i])
-	}
-
-	go func() {
-		wg.Wait()
-		close(linksChan)
-	}()
-
-	var endRes DownloadInfos
-	for link := range linksChan {
-		if link.EpisodeDirectDownloadLink == "" {
-			continue
-		}
-		endRes = append(endRes, link)
-		if len(endRes) == maxNbOfLinks {
-			return endRes, nil
-		}
+	endRes, err := s.resolveLinks(ctx, animeName, episodeNb, dwnLinks, maxNbOfLinks)
+	if err != nil {
+		return DownloadInfos{}, err
 	}
 
+	s.client.metrics.ObserveBackupFallback(len(endRes) == 0)
 	if len(endRes) == 0 {
-		return s.GetBackupLinks(animeName, episodeNb)
+		s.client.logger.InfoContext(ctx, "no direct link decoded, falling back to backup links",
+			slog.String("anime", animeName),
+			slog.Int("episode", episodeNb),
+			slog.Int("links", len(dwnLinks)))
+		return s.GetBackupLinksWithContext(ctx, animeName, episodeNb)
 	}
 
 	return endRes, nil
 }
 
 func (s *EpisodeService) GetBackupLinks(animeName string, episodeNb int) (DownloadInfos, error) {
+	return s.GetBackupLinksWithContext(context.Background(), animeName, episodeNb)
+}
+
+func (s *EpisodeService) GetBackupLinksWithContext(ctx context.Context, animeName string, episodeNb int) (DownloadInfos, error) {
 	var endRes []DownloadInfo
 	if s.client.cfg.backupLinksSecret != "" {
-		apiUrl := BaseAPI
-		resource := BackupLinksPath
 		data := url.Values{}
 		data.Set("f", animeName)
 		data.Set("e", fmt.Sprintf("%d", episodeNb))
 		data.Set("inf", `{"a": "4+mwbwVfA5wLr7a4GBQvzMy1/jO9fRQ/lKJXNS4vbW/FqNL3j0vtOPd5pQx2UxrJ/8UF0Xr/v/dxkse3tjvEg/1uLKKZM8CALrQrGtw0pQqZ+UiyBJqVXe9tlbFSkV9XQRkIC6qjY66uzkzk6wauPw==", "b": "217.138.207.148"}`)
 
-		u, _ := url.ParseRequestURI(apiUrl)
-		u.Path = resource
-		urlStr := u.String()
+		urlStr, err := s.client.url(BackupLinksPath, nil)
+		if err != nil {
+			return DownloadInfos{}, err
+		}
 
-		client := &http.Client{}
-		r, _ := http.NewRequest(http.MethodPost, urlStr, strings.NewReader(data.Encode())) // URL-encoded payload
+		// like file hosts, the backup links endpoint gets no credentials
+		r, err := s.client.newFileHostRequest(ctx, http.MethodPost, urlStr, strings.NewReader(data.Encode())) // URL-encoded payload
+		if err != nil {
+			return DownloadInfos{}, err
+		}
 		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-		res, err := client.Do(r)
+		if err := s.client.waitRateLimit(ctx, BackupLinksPath); err != nil {
+			return DownloadInfos{}, err
+		}
+		start := time.Now()
+		res, err := s.client.client.Do(r)
 		if err != nil {
+			s.client.metrics.ObserveRequest(BackupLinksPath, time.Since(start), err)
 			return DownloadInfos{}, err
 		}
 		defer res.Body.Close()
@@ -275,6 +340,13 @@ func (s *EpisodeService) GetBackupLinks(animeName string, episodeNb int) (Downlo
 		if err != nil {
 			return DownloadInfos{}, err
 		}
+		if res.StatusCode != http.StatusOK {
+			err = newAPIError(res, body)
+		}
+		s.client.metrics.ObserveRequest(BackupLinksPath, time.Since(start), err)
+		if err != nil {
+			return DownloadInfos{}, err
+		}
 
 		var backuplinks BackupLinks
 		encrypted, err := base64.StdEncoding.DecodeString(string(body))
@@ -288,13 +360,26 @@ func (s *EpisodeService) GetBackupLinks(animeName string, episodeNb int) (Downlo
 		if err := json.Unmarshal(decrypted, &backuplinks); err != nil {
 			return DownloadInfos{}, ErrBackupLink
 		}
-		for _, bl := range backuplinks {
-			endRes = append(endRes, DownloadInfo{"Backup link", bl.File})
+		for i, bl := range backuplinks {
+			endRes = append(endRes, DownloadInfo{
+				EpisodeHostLink:           "Backup link",
+				EpisodeDirectDownloadLink: bl.File,
+				Host:                      linkHost(bl.File),
+				Label:                     bl.Label,
+				Index:                     i,
+			})
 		}
 	}
 	if len(endRes) == 0 {
 		return DownloadInfos{}, fmt.Errorf("all links are dead")
 	}
+	if s.client.probe {
+		endRes = s.ProbeDownloadInfosWithContext(ctx, endRes)
+		if len(endRes) == 0 {
+			return DownloadInfos{}, fmt.Errorf("all links are dead")
+		}
+	}
+	s.client.ranking.sort(endRes)
 	return endRes, nil
 }
 
@@ -0,0 +1,109 @@
+package tohru
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// episodeServer answers get-episodes-new with respond and records the payloads.
+type episodeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []JsonPayload
+}
+
+func newEpisodeServer(t *testing.T, respond func(w http.ResponseWriter, payload JsonPayload)) *episodeServer {
+	t.Helper()
+	es := &episodeServer{}
+	es.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload JsonPayload
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal([]byte(r.PostForm.Get("json")), &payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		es.mu.Lock()
+		es.payloads = append(es.payloads, payload)
+		es.mu.Unlock()
+		respond(w, payload)
+	}))
+	t.Cleanup(es.Close)
+	return es
+}
+
+func (es *episodeServer) last() JsonPayload {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	return es.payloads[len(es.payloads)-1]
+}
+
+func TestGetEpisodeDetailsNotFound(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantAPI bool
+	}{
+		{"404", http.StatusNotFound, `{"title":"Not Found","detail":"no such episode"}`, true},
+		{"empty", http.StatusOK, `{"response":{"data":[],"count":0}}`, false},
+		{"other episode", http.StatusOK, `{"response":{"data":[{"episode_id":"8"}],"count":1}}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := newEpisodeServer(t, func(w http.ResponseWriter, _ JsonPayload) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(es.URL)).EpisodeService
+
+			_, err := s.GetEpisodeDetails(1, 7)
+			if !errors.Is(err, ErrEpisodeNotFound) {
+				t.Fatalf("got %v, want ErrEpisodeNotFound", err)
+			}
+			var apiErr *APIError
+			if errors.As(err, &apiErr) != tt.wantAPI {
+				t.Errorf("errors.As(*APIError) = %v, want %v", !tt.wantAPI, tt.wantAPI)
+			}
+		})
+	}
+}
+
+func TestGetEpisodeDetails(t *testing.T) {
+	es := newEpisodeServer(t, func(w http.ResponseWriter, _ JsonPayload) {
+		_, _ = w.Write([]byte(`{"response":{"data":[{"episode_id":"6"},{"episode_id":"7","episode_name":"seven"}],"count":2}}`))
+	})
+	s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(es.URL)).EpisodeService
+
+	ep, err := s.GetEpisodeDetails(1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep.EpisodeName != "seven" {
+		t.Errorf("got episode %+v, want episode 7", ep)
+	}
+}
+
+func TestCountEpisodes(t *testing.T) {
+	es := newEpisodeServer(t, func(w http.ResponseWriter, _ JsonPayload) {
+		_, _ = w.Write([]byte(`{"response":{"data":[{"episode_id":"1"}],"count":24}}`))
+	})
+	s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(es.URL)).EpisodeService
+
+	n, err := s.CountEpisodes(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 24 {
+		t.Errorf("count = %d, want 24", n)
+	}
+	if limit := es.last()["_limit"]; limit != float64(1) {
+		t.Errorf("_limit = %v, want 1", limit)
+	}
+}
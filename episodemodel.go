@@ -0,0 +1,215 @@
+package tohru
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SkipInterval is the part of an episode, usually the opening, players may skip.
+type SkipInterval struct {
+	From time.Duration
+	To   time.Duration
+}
+
+func (i SkipInterval) Empty() bool {
+	return i.To <= i.From
+}
+
+// Contains reports whether position is inside the interval.
+func (i SkipInterval) Contains(position time.Duration) bool {
+	return !i.Empty() && position >= i.From && position < i.To
+}
+
+// TypedEpisode is Episode with its fields parsed, Raw keeps the server values.
+type TypedEpisode struct {
+	Raw             Episode
+	ID              int
+	Name            string
+	Number          float64
+	AllowComment    bool
+	Skip            SkipInterval
+	Rating          float64
+	RatingUserCount int
+	Watched         bool
+	RatedByUser     bool
+	UserRating      float64
+	Next            *EpisodeRef
+	Previous        *EpisodeRef
+}
+
+// Typed parses e, the returned error lists every field that failed to parse
+// and the matching fields are left to their zero value.
+func (e Episode) Typed() (TypedEpisode, error) {
+	var p fieldParser
+	t := TypedEpisode{
+		Raw:             e,
+		ID:              p.int("episode_id", e.EpisodeID),
+		Name:            e.EpisodeName,
+		Number:          p.float("episode_number", e.EpisodeNumber),
+		AllowComment:    anyBool(e.AllowComment),
+		Rating:          p.float("episode_rating", e.EpisodeRating),
+		RatingUserCount: p.int("episode_rating_user_count", e.EpisodeRatingUserCount),
+		Watched:         anyBool(e.EpisodeWatchedHistory),
+		RatedByUser:     anyBool(e.EpisodeAlreadyRatedByUser),
+		UserRating:      p.float("episode_rating_by_user", anyString(e.EpisodeRatingByUser)),
+		Skip: SkipInterval{
+			From: p.offset("skip_from", e.SkipFrom),
+			To:   p.offset("skip_to", e.SkipTo),
+		},
+	}
+	if len(e.NextEpisode) > 0 {
+		t.Next = &e.NextEpisode[0]
+	}
+	if ref, ok := e.PreviousEpisode.ref(); ok {
+		t.Previous = &ref
+	}
+	return t, p.err()
+}
+
+// episodeRefs decodes next_episode. The API sends an array of references,
+// a single object, or false, null, [] and {} when there is none. Any other
+// value is taken for none rather than failing the whole response.
+type episodeRefs []EpisodeRef
+
+func (r *episodeRefs) UnmarshalJSON(b []byte) error {
+	var values looseList
+	if err := values.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	*r = nil
+	for _, v := range values {
+		if ref, ok := episodeRef(v); ok {
+			*r = append(*r, ref)
+		}
+	}
+	return nil
+}
+
+// looseList decodes previous_episode, kept as raw values for compatibility,
+// with the same shapes as episodeRefs.
+type looseList []interface{}
+
+func (l *looseList) UnmarshalJSON(b []byte) error {
+	*l = nil
+	if emptyJSON(b) {
+		return nil
+	}
+	var values []interface{}
+	if err := json.Unmarshal(b, &values); err == nil {
+		*l = values
+		return nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(b, &object); err == nil {
+		*l = looseList{object}
+	}
+	return nil
+}
+
+// ref parses the first value of l as an episode reference.
+func (l looseList) ref() (EpisodeRef, bool) {
+	if len(l) == 0 {
+		return EpisodeRef{}, false
+	}
+	return episodeRef(l[0])
+}
+
+// episodeRef parses a decoded {"episode_id", "episode_name"} object,
+// the id may be a string or a number.
+func episodeRef(v interface{}) (EpisodeRef, bool) {
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		return EpisodeRef{}, false
+	}
+	ref := EpisodeRef{
+		EpisodeID:   anyString(object["episode_id"]),
+		EpisodeName: anyString(object["episode_name"]),
+	}
+	return ref, ref.EpisodeID != ""
+}
+
+// offset parses a position inside an episode given in seconds or as [hh:]mm:ss.
+func (p *fieldParser) offset(field, value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(secs * float64(time.Second))
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		p.fail(field, value, errors.New("unknown offset format"))
+		return 0
+	}
+	var d time.Duration
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			p.fail(field, value, err)
+			return 0
+		}
+		d = d*60 + time.Duration(n)
+	}
+	return d * time.Second
+}
+
+// anyBool converts the loosely typed flags of the API to a bool.
+func anyBool(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(v) {
+		case "yes", "true", "1":
+			return true
+		}
+		return false
+	case float64:
+		return v != 0
+	case []interface{}:
+		// the API sends [] for empty values
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return false
+	}
+}
+
+// Next fetches the episode following ep.
+func (s *EpisodeService) Next(animeID int, ep Episode) (Episode, error) {
+	return s.NextWithContext(context.Background(), animeID, ep)
+}
+
+func (s *EpisodeService) NextWithContext(ctx context.Context, animeID int, ep Episode) (Episode, error) {
+	if len(ep.NextEpisode) == 0 {
+		return Episode{}, ErrNoNextEpisode
+	}
+	return s.adjacent(ctx, animeID, ep.NextEpisode[0])
+}
+
+// Previous fetches the episode preceding ep.
+func (s *EpisodeService) Previous(animeID int, ep Episode) (Episode, error) {
+	return s.PreviousWithContext(context.Background(), animeID, ep)
+}
+
+func (s *EpisodeService) PreviousWithContext(ctx context.Context, animeID int, ep Episode) (Episode, error) {
+	ref, ok := ep.PreviousEpisode.ref()
+	if !ok {
+		return Episode{}, ErrNoPreviousEpisode
+	}
+	return s.adjacent(ctx, animeID, ref)
+}
+
+func (s *EpisodeService) adjacent(ctx context.Context, animeID int, ref EpisodeRef) (Episode, error) {
+	id, err := strconv.Atoi(ref.EpisodeID)
+	if err != nil {
+		return Episode{}, err
+	}
+	return s.GetEpisodeDetailsWithContext(ctx, animeID, id)
+}
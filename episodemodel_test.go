@@ -0,0 +1,101 @@
+package tohru
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestEpisodeTyped(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		watched bool
+		rated   bool
+	}{
+		{"empty arrays", `{"episode_watched_history":[],"episode_already_rated_by_user":[]}`, false, false},
+		{"empty objects", `{"episode_watched_history":{},"episode_already_rated_by_user":{}}`, false, false},
+		{"null", `{"episode_watched_history":null,"episode_already_rated_by_user":null}`, false, false},
+		{"values", `{"episode_watched_history":{"watched_at":"2020-01-01"},"episode_already_rated_by_user":"Yes"}`, true, true},
+		{"non empty array", `{"episode_watched_history":[{"id":"1"}],"episode_already_rated_by_user":true}`, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ep Episode
+			if err := json.Unmarshal([]byte(tt.json), &ep); err != nil {
+				t.Fatal(err)
+			}
+			typed, err := ep.Typed()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typed.Watched != tt.watched || typed.RatedByUser != tt.rated {
+				t.Errorf("Watched, RatedByUser = %v, %v, want %v, %v", typed.Watched, typed.RatedByUser, tt.watched, tt.rated)
+			}
+		})
+	}
+}
+
+func TestEpisodeSkipInterval(t *testing.T) {
+	ep := Episode{SkipFrom: "90", SkipTo: "02:30"}
+	typed, err := ep.Typed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SkipInterval{From: 90 * time.Second, To: 150 * time.Second}
+	if typed.Skip != want {
+		t.Errorf("Skip = %+v, want %+v", typed.Skip, want)
+	}
+	if !typed.Skip.Contains(2*time.Minute) || typed.Skip.Contains(3*time.Minute) {
+		t.Error("unexpected Contains result")
+	}
+}
+
+func TestEpisodeAdjacentShapes(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"array", `[{"episode_id":"12","episode_name":"Episode 12"}]`, "12"},
+		{"object", `{"episode_id":"12","episode_name":"Episode 12"}`, "12"},
+		{"numeric id", `[{"episode_id":12}]`, "12"},
+		{"false", `false`, ""},
+		{"null", `null`, ""},
+		{"empty array", `[]`, ""},
+		{"empty object", `{}`, ""},
+		{"unexpected value", `"none"`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var res episodesResponse
+			data := `{"data":[{"episode_id":"11","next_episode":` + tt.json + `,"previous_episode":` + tt.json + `}],"count":1}`
+			if err := json.Unmarshal([]byte(data), &res); err != nil {
+				t.Fatal(err)
+			}
+			typed, err := res.Episodes[0].Typed()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for field, ref := range map[string]*EpisodeRef{"Next": typed.Next, "Previous": typed.Previous} {
+				switch {
+				case tt.want == "" && ref != nil:
+					t.Errorf("%s = %+v, want nil", field, ref)
+				case tt.want != "" && (ref == nil || ref.EpisodeID != tt.want):
+					t.Errorf("%s = %+v, want episode %s", field, ref, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPreviousEpisodeKeepsRawValues(t *testing.T) {
+	var ep Episode
+	if err := json.Unmarshal([]byte(`{"previous_episode":[{"episode_id":"3"}]}`), &ep); err != nil {
+		t.Fatal(err)
+	}
+	var raw []interface{} = ep.PreviousEpisode
+	if object, ok := raw[0].(map[string]interface{}); !ok || object["episode_id"] != "3" {
+		t.Errorf("PreviousEpisode = %v", raw)
+	}
+}
@@ -0,0 +1,196 @@
+package tohru
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// EpisodeQuery is a typed builder for the episodes endpoint.
+// Range, Since and Order are also applied to the returned episodes so the
+// result is correct even when the server ignores them, Order is then only
+// guaranteed within a page.
+//
+//	q := tohru.NewEpisodeQuery(animeID).Range(1, 12).Order(tohru.EpisodeNumberDesc)
+//	episodes, err := client.EpisodeService.Query(q)
+type EpisodeQuery struct {
+	animeID   int
+	rangeFrom int
+	rangeTo   int
+	sinceID   int
+	order     episodeOrder
+	offset    int
+	limit     int
+}
+
+func NewEpisodeQuery(animeID int) *EpisodeQuery {
+	return &EpisodeQuery{
+		animeID: animeID,
+		order:   EpisodeNumberAsc,
+		limit:   DefaultQueryLimit,
+	}
+}
+
+// Range restricts episode numbers to the inclusive range [from, to].
+func (q *EpisodeQuery) Range(from, to int) *EpisodeQuery {
+	q.rangeFrom, q.rangeTo = from, to
+	return q
+}
+
+// Since keeps the episodes added after the episode with the given ID.
+func (q *EpisodeQuery) Since(episodeID int) *EpisodeQuery {
+	q.sinceID = episodeID
+	return q
+}
+
+func (q *EpisodeQuery) Order(o episodeOrder) *EpisodeQuery {
+	q.order = o
+	return q
+}
+
+func (q *EpisodeQuery) Offset(offset int) *EpisodeQuery {
+	q.offset = offset
+	return q
+}
+
+func (q *EpisodeQuery) Limit(limit int) *EpisodeQuery {
+	q.limit = limit
+	return q
+}
+
+func (q *EpisodeQuery) Validate() error {
+	_, err := q.payload(q.offset, q.limit)
+	return err
+}
+
+func (q *EpisodeQuery) Payload() (JsonPayload, error) {
+	return q.payload(q.offset, q.limit)
+}
+
+func (q *EpisodeQuery) payload(offset, limit int) (JsonPayload, error) {
+	payload := make(JsonPayload)
+	var errs []error
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	check(payload.WithJustInfo("No"))
+	check(payload.WithAnimeId(q.animeID))
+	check(payload.WithOffset(offset))
+	check(payload.WithLimit(limit))
+	check(payload.WithEpisodeOrder(q.order))
+	if q.rangeFrom != 0 || q.rangeTo != 0 {
+		check(payload.WithEpisodeRange(q.rangeFrom, q.rangeTo))
+	}
+	if q.sinceID != 0 {
+		check(payload.WithSinceEpisodeId(q.sinceID))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return payload, nil
+}
+
+func (q *EpisodeQuery) String() string {
+	payload, err := q.Payload()
+	if err != nil {
+		return "invalid query: " + err.Error()
+	}
+	s, err := payload.ToJson()
+	if err != nil {
+		return "invalid query: " + err.Error()
+	}
+	return s
+}
+
+func (q *EpisodeQuery) match(ep Episode) bool {
+	if q.rangeFrom != 0 || q.rangeTo != 0 {
+		n, err := strconv.ParseFloat(ep.EpisodeNumber, 64)
+		if err != nil || n < float64(q.rangeFrom) || n > float64(q.rangeTo) {
+			return false
+		}
+	}
+	if q.sinceID != 0 {
+		id, err := strconv.Atoi(ep.EpisodeID)
+		if err != nil || id <= q.sinceID {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *EpisodeQuery) filter(episodes []Episode) []Episode {
+	filtered := make([]Episode, 0, len(episodes))
+	for _, ep := range episodes {
+		if q.match(ep) {
+			filtered = append(filtered, ep)
+		}
+	}
+	slices.SortStableFunc(filtered, func(a, b Episode) int {
+		c := cmp.Compare(episodeNumber(a), episodeNumber(b))
+		if q.order == EpisodeNumberDesc {
+			return -c
+		}
+		return c
+	})
+	return filtered
+}
+
+// episodeNumber parses the episode number, unparsable numbers sort last.
+func episodeNumber(ep Episode) float64 {
+	n, err := strconv.ParseFloat(ep.EpisodeNumber, 64)
+	if err != nil {
+		return math.Inf(1)
+	}
+	return n
+}
+
+func (s *EpisodeService) Query(q *EpisodeQuery) ([]Episode, error) {
+	return s.QueryWithContext(context.Background(), q)
+}
+
+func (s *EpisodeService) QueryWithContext(ctx context.Context, q *EpisodeQuery) ([]Episode, error) {
+	payload, err := q.Payload()
+	if err != nil {
+		return []Episode{}, err
+	}
+	res, err := s.getEpisodes(ctx, payload)
+	if err != nil {
+		return []Episode{}, err
+	}
+	return q.filter(res.Episodes), nil
+}
+
+// QueryPager walks q starting at its offset, q.Limit at a time.
+// The episodes endpoint echoes no limit, the pager stops once it walked
+// past the episode count instead.
+func (s *EpisodeService) QueryPager(q *EpisodeQuery) *Pager[Episode] {
+	count := -1
+	p := newPager(q.limit, func(ctx context.Context, offset, limit int) ([]Episode, MetaData, int, error) {
+		meta := MetaData{
+			Offset:  strconv.Itoa(offset),
+			OrderBy: string(q.order),
+		}
+		if count >= 0 && offset >= count {
+			return nil, meta, 0, nil
+		}
+		payload, err := q.payload(offset, limit)
+		if err != nil {
+			return nil, MetaData{}, 0, err
+		}
+		res, err := s.getEpisodes(ctx, payload)
+		if err != nil {
+			return nil, MetaData{}, 0, err
+		}
+		count = res.Count
+		return q.filter(res.Episodes), meta, len(res.Episodes), nil
+	})
+	p.offset = q.offset
+	return p
+}
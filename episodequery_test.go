@@ -0,0 +1,69 @@
+package tohru
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestEpisodeQueryAppliesFiltersClientSide(t *testing.T) {
+	// the server ignores every filter and the order
+	es := newEpisodeServer(t, func(w http.ResponseWriter, _ JsonPayload) {
+		_, _ = w.Write([]byte(`{"response":{"data":[
+			{"episode_id":"11","episode_number":"1"},
+			{"episode_id":"12","episode_number":"2"},
+			{"episode_id":"13","episode_number":"3"},
+			{"episode_id":"14","episode_number":"4"},
+			{"episode_id":"15","episode_number":"5"}
+		],"count":5}}`))
+	})
+	s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(es.URL)).EpisodeService
+
+	q := NewEpisodeQuery(1).Range(2, 5).Since(12).Order(EpisodeNumberDesc).Limit(5)
+	episodes, err := s.Query(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, ep := range episodes {
+		got = append(got, ep.EpisodeNumber)
+	}
+	want := []string{"5", "4", "3"}
+	if len(got) != len(want) {
+		t.Fatalf("got episodes %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got episodes %v, want %v", got, want)
+		}
+	}
+
+	payload := es.last()
+	if payload["_order_by"] != string(EpisodeNumberDesc) {
+		t.Errorf("_order_by = %v, want %s", payload["_order_by"], EpisodeNumberDesc)
+	}
+}
+
+func TestEpisodeQueryPager(t *testing.T) {
+	es := newEpisodeServer(t, func(w http.ResponseWriter, payload JsonPayload) {
+		switch payload["_offset"] {
+		case float64(0):
+			_, _ = w.Write([]byte(`{"response":{"data":[{"episode_id":"1","episode_number":"1"},{"episode_id":"2","episode_number":"2"}],"count":3}}`))
+		default:
+			_, _ = w.Write([]byte(`{"response":{"data":[{"episode_id":"3","episode_number":"3"}],"count":3}}`))
+		}
+	})
+	s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(es.URL)).EpisodeService
+
+	var ids []string
+	for ep, err := range s.QueryPager(NewEpisodeQuery(1).Limit(2)).All(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, ep.EpisodeID)
+	}
+	if len(ids) != 3 || ids[2] != "3" {
+		t.Fatalf("got episodes %v, want [1 2 3]", ids)
+	}
+}
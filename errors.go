@@ -0,0 +1,65 @@
+package tohru
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrNotFound     = errors.New("resource not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// APIError is returned for every non 200 response of the Anslayer API.
+type APIError struct {
+	StatusCode int
+	Title      string
+	Detail     string
+	Path       string
+	Body       []byte
+}
+
+func newAPIError(res *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		Body:       body,
+	}
+	if res.Request != nil && res.Request.URL != nil {
+		apiErr.Path = res.Request.URL.Path
+	}
+
+	var errRes errorRes
+	if err := json.Unmarshal(body, &errRes); err == nil {
+		apiErr.Title = errRes.Title
+		apiErr.Detail = errRes.Detail
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	if e.Title == "" && e.Detail == "" {
+		return fmt.Sprintf("%s : %d %s", e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%s : %s", e.Title, e.Detail)
+}
+
+// Unwrap maps the status code to one of the package sentinel errors
+// so callers can use errors.Is(err, ErrNotFound) and friends.
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrServer
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,281 @@
+package tohru
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Genre is an Anslayer genre. ID and ArabicName are the values sent by the
+// API, Name is the English translation when known.
+type Genre struct {
+	ID         int
+	Name       string
+	ArabicName string
+}
+
+// knownGenres seeds the genre catalog of every client, so genres can be
+// listed and filtered by name before any anime was fetched. Anslayer
+// publishes no genre list and these ids are not confirmed by the API, the
+// genres it returns replace them.
+var knownGenres = []Genre{
+	{1, "Action", "أكشن"},
+	{2, "Adventure", "مغامرات"},
+	{3, "Cars", "سيارات"},
+	{4, "Comedy", "كوميدي"},
+	{5, "Dementia", "جنون"},
+	{6, "Demons", "شياطين"},
+	{7, "Mystery", "غموض"},
+	{8, "Drama", "دراما"},
+	{9, "Ecchi", "إيتشي"},
+	{10, "Fantasy", "خيال"},
+	{11, "Game", "ألعاب"},
+	{12, "Historical", "تاريخي"},
+	{13, "Horror", "رعب"},
+	{14, "Kids", "أطفال"},
+	{15, "Magic", "سحر"},
+	{16, "Martial Arts", "فنون قتالية"},
+	{17, "Mecha", "ميكا"},
+	{18, "Music", "موسيقى"},
+	{19, "Parody", "محاكاة ساخرة"},
+	{20, "Samurai", "ساموراي"},
+	{21, "Romance", "رومانسي"},
+	{22, "School", "مدرسي"},
+	{23, "Sci-Fi", "خيال علمي"},
+	{24, "Shoujo", "شوجو"},
+	{25, "Shounen", "شونين"},
+	{26, "Space", "فضاء"},
+	{27, "Sports", "رياضي"},
+	{28, "Super Power", "قوى خارقة"},
+	{29, "Vampire", "مصاصي دماء"},
+	{30, "Harem", "حريم"},
+	{31, "Slice of Life", "شريحة من الحياة"},
+	{32, "Supernatural", "خارق للطبيعة"},
+	{33, "Military", "عسكري"},
+	{34, "Police", "بوليسي"},
+	{35, "Psychological", "نفسي"},
+	{36, "Thriller", "إثارة"},
+	{37, "Seinen", "سينين"},
+	{38, "Josei", "جوسي"},
+}
+
+// englishGenreNames translates the Arabic genre names used by Anslayer.
+var englishGenreNames = func() map[string]string {
+	names := make(map[string]string, len(knownGenres))
+	for _, g := range knownGenres {
+		names[g.ArabicName] = g.Name
+	}
+	return names
+}()
+
+// genreCatalog maps genre ids to names. Each client has its own, seeded with
+// knownGenres and updated with the genres of the animes it fetches. A name
+// belongs to a single id, the last one registered.
+type genreCatalog struct {
+	mu     sync.RWMutex
+	byID   map[int]Genre
+	byName map[string]int
+}
+
+func newGenreCatalog() *genreCatalog {
+	c := &genreCatalog{
+		byID:   make(map[int]Genre),
+		byName: make(map[string]int),
+	}
+	c.register(knownGenres...)
+	return c
+}
+
+// seedGenres resolves genre names where no client is at hand, it is never
+// updated.
+var seedGenres = newGenreCatalog()
+
+func (c *genreCatalog) register(genres ...Genre) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, g := range genres {
+		if g.Name == "" {
+			g.Name = englishGenreNames[g.ArabicName]
+		}
+		if old, ok := c.byID[g.ID]; ok {
+			c.unname(old)
+		}
+		for _, key := range genreKeys(g) {
+			if id, ok := c.byName[key]; ok && id != g.ID {
+				// the server moved the name to another id
+				c.unname(c.byID[id])
+				delete(c.byID, id)
+			}
+			c.byName[key] = g.ID
+		}
+		c.byID[g.ID] = g
+	}
+}
+
+func (c *genreCatalog) unname(g Genre) {
+	for _, key := range genreKeys(g) {
+		if c.byName[key] == g.ID {
+			delete(c.byName, key)
+		}
+	}
+}
+
+func (c *genreCatalog) all() []Genre {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	genres := make([]Genre, 0, len(c.byID))
+	for _, g := range c.byID {
+		genres = append(genres, g)
+	}
+	slices.SortFunc(genres, func(a, b Genre) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return genres
+}
+
+func (c *genreCatalog) get(id int) (Genre, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	g, ok := c.byID[id]
+	return g, ok
+}
+
+func (c *genreCatalog) lookup(name string) (Genre, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	id, ok := c.byName[genreKey(name)]
+	if !ok {
+		return Genre{}, false
+	}
+	return c.byID[id], true
+}
+
+func (c *genreCatalog) ids(names []string) ([]int, error) {
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		g, ok := c.lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown genre %q, see LoadGenres", name)
+		}
+		ids = append(ids, g.ID)
+	}
+	return ids, nil
+}
+
+// genreKeys returns the lookup keys of the English and Arabic names of g.
+func genreKeys(g Genre) []string {
+	var keys []string
+	for _, name := range []string{g.Name, g.ArabicName} {
+		if key := genreKey(name); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func genreKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ParseGenres pairs the comma joined ids and names returned by the API,
+// the names sent by the server are always kept.
+func ParseGenres(ids, names string) []Genre {
+	idList := splitList(ids)
+	nameList := splitList(names)
+
+	var parsed []Genre
+	for i, rawID := range idList {
+		id, err := strconv.Atoi(rawID)
+		if err != nil {
+			continue
+		}
+		g := Genre{ID: id}
+		if i < len(nameList) {
+			g.ArabicName = nameList[i]
+			g.Name = englishGenreNames[g.ArabicName]
+		}
+		parsed = append(parsed, g)
+	}
+	return parsed
+}
+
+// Studio is an animation studio referenced by MoreInfoResult.
+type Studio struct {
+	ID   int
+	Name string
+}
+
+// Studios pairs AnimeStudioIds with AnimeStudios.
+func (m moreInfoResult) Studios() []Studio {
+	idList := splitList(m.AnimeStudioIds)
+	nameList := splitList(m.AnimeStudios)
+
+	var studios []Studio
+	for i, rawID := range idList {
+		id, err := strconv.Atoi(rawID)
+		if err != nil {
+			continue
+		}
+		s := Studio{ID: id}
+		if i < len(nameList) {
+			s.Name = nameList[i]
+		}
+		studios = append(studios, s)
+	}
+	return studios
+}
+
+// Genres returns the genres of the anime.
+func (ad AnimeDetails) Genres() []Genre {
+	return ParseGenres(ad.AnimeGenreIds, ad.AnimeGenres)
+}
+
+// GetGenres lists every genre known to the client ordered by ID,
+// see LoadGenres.
+func (s *AnimeService) GetGenres() []Genre {
+	return s.client.genres.all()
+}
+
+func (s *AnimeService) GenreByID(id int) (Genre, bool) {
+	return s.client.genres.get(id)
+}
+
+// GenreByName looks a genre up by its English or Arabic name.
+func (s *AnimeService) GenreByName(name string) (Genre, bool) {
+	return s.client.genres.lookup(name)
+}
+
+// RegisterGenres adds genres to the client catalog, e.g. from a list kept
+// by the caller. A genre replaces the one of the same id or name.
+func (s *AnimeService) RegisterGenres(genres ...Genre) {
+	s.client.genres.register(genres...)
+}
+
+// LoadGenres updates the genre catalog from the details of the given animes.
+func (s *AnimeService) LoadGenres(animeIDs ...int) ([]Genre, error) {
+	return s.LoadGenresWithContext(context.Background(), animeIDs...)
+}
+
+func (s *AnimeService) LoadGenresWithContext(ctx context.Context, animeIDs ...int) ([]Genre, error) {
+	for _, id := range animeIDs {
+		if _, err := s.GetAnimeDetailsWithContext(ctx, id); err != nil {
+			return s.GetGenres(), err
+		}
+	}
+	return s.GetGenres(), nil
+}
+
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
@@ -0,0 +1,97 @@
+package tohru
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseGenresKeepsServerNames(t *testing.T) {
+	genres := ParseGenres("1, 99", "اسم من الخادم, نوع جديد")
+	want := []Genre{
+		{ID: 1, ArabicName: "اسم من الخادم"},
+		{ID: 99, ArabicName: "نوع جديد"},
+	}
+	if len(genres) != len(want) {
+		t.Fatalf("got %v, want %v", genres, want)
+	}
+	for i := range want {
+		if genres[i] != want[i] {
+			t.Errorf("genre %d = %+v, want %+v", i, genres[i], want[i])
+		}
+	}
+}
+
+func TestKnownGenresWithoutPriming(t *testing.T) {
+	s := NewTohruClient(NewConfig("id", "secret", "")).AnimeService
+	if got := len(s.GetGenres()); got != len(knownGenres) {
+		t.Errorf("GetGenres() returned %d genres, want %d", got, len(knownGenres))
+	}
+	g, ok := s.GenreByName("comedy")
+	if !ok || g.ArabicName != "كوميدي" {
+		t.Errorf("GenreByName(comedy) = %+v, %v", g, ok)
+	}
+
+	q := NewAnimeQuery().GenreNames("Action", "رعب")
+	payload, err := q.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload["anime_genre_ids"] != "1,13" {
+		t.Errorf("anime_genre_ids = %v, want 1,13", payload["anime_genre_ids"])
+	}
+	if err := NewAnimeQuery().GenreNames("Unknown").Validate(); err == nil {
+		t.Error("expected an error for an unknown genre")
+	}
+}
+
+func TestGenreCatalogPerClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"response":{"anime_id":"7","anime_genre_ids":"301,302","anime_genres":"كوميدي,نوع غير معروف"}}`))
+	}))
+	defer srv.Close()
+	s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL)).AnimeService
+	other := NewTohruClient(NewConfig("id", "secret", "")).AnimeService
+
+	if _, err := s.LoadGenres(7); err != nil {
+		t.Fatal(err)
+	}
+
+	g, ok := s.GenreByName("Comedy")
+	if !ok || g.ID != 301 || g.ArabicName != "كوميدي" {
+		t.Errorf("GenreByName(Comedy) = %+v, %v", g, ok)
+	}
+	if _, ok := s.GenreByID(4); ok {
+		t.Error("the built-in Comedy id was kept after the server sent another one")
+	}
+	g, ok = s.GenreByID(302)
+	if !ok || g.ArabicName != "نوع غير معروف" || g.Name != "" {
+		t.Errorf("GenreByID(302) = %+v, %v", g, ok)
+	}
+
+	if g, _ := other.GenreByName("Comedy"); g.ID != 4 {
+		t.Errorf("other client Comedy id = %d, want the built-in 4", g.ID)
+	}
+	if _, ok := other.GenreByID(302); ok {
+		t.Error("genre learned by one client leaked to another")
+	}
+}
+
+func TestQueryUsesClientGenres(t *testing.T) {
+	var sent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.URL.Query().Get("json")
+		_, _ = w.Write([]byte(`{"response":{"data":[]}}`))
+	}))
+	defer srv.Close()
+	s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL)).AnimeService
+	s.RegisterGenres(Genre{ID: 302, ArabicName: "نوع غير معروف"})
+
+	if _, err := s.Query(NewAnimeQuery().GenreNames("نوع غير معروف", "Action")); err != nil {
+		t.Fatal(err)
+	}
+	if want := `"anime_genre_ids":"302,1"`; !strings.Contains(sent, want) {
+		t.Errorf("sent %s, want %s", sent, want)
+	}
+}
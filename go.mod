@@ -1,6 +1,6 @@
 module github.com/khatibomar/tohru
 
-go 1.22
+go 1.23
 
 require (
 	github.com/RNCryptor/RNCryptor-go v0.1.0
@@ -0,0 +1,181 @@
+package tohru
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultDecodeConcurrency = 4
+	DefaultDecodeTimeout     = 30 * time.Second
+)
+
+// WithDecodeConcurrency limits the number of links resolved at the same time.
+func WithDecodeConcurrency(n int) Option {
+	return func(t *TohruClient) {
+		if n > 0 {
+			t.decodeConcurrency = n
+		}
+	}
+}
+
+// WithDecodeTimeout bounds the time spent resolving a single link.
+func WithDecodeTimeout(timeout time.Duration) Option {
+	return func(t *TohruClient) {
+		if timeout > 0 {
+			t.decodeTimeout = timeout
+		}
+	}
+}
+
+type linkJob struct {
+	link  string
+	index int
+}
+
+// resolveLinks resolves links with a bounded pool of workers and returns
+// the best max links according to the client ranking. Outstanding links are
+// cancelled as soon as the ranking cannot change the result anymore.
+// Every worker has exited when it returns.
+func (s *EpisodeService) resolveLinks(ctx context.Context, animeName string, episodeNb int, links DownloadLinks, max int) (DownloadInfos, error) {
+	done := s.client.ranking.stopMode().tracker(len(links), max)
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	workers := min(s.client.decodeConcurrency, len(links))
+	jobs := make(chan linkJob)
+	// buffered so workers never block on a result nobody reads
+	results := make(chan DownloadInfo, len(links))
+
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				results <- s.resolveLink(ctx, animeName, episodeNb, job.link, job.index)
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for i, link := range links {
+			select {
+			case jobs <- linkJob{link, i}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	settled := make([]bool, len(links))
+	defer func() {
+		for i, ok := range settled {
+			if !ok {
+				s.client.metrics.ObserveLinkDecode(linkHost(links[i]), false)
+			}
+		}
+	}()
+
+	var endRes DownloadInfos
+	for pending := len(links); pending > 0; pending-- {
+		select {
+		case <-ctx.Done():
+			return DownloadInfos{}, ctx.Err()
+		case link := <-results:
+			decoded := link.EpisodeDirectDownloadLink != ""
+			settled[link.Index] = true
+			s.client.metrics.ObserveLinkDecode(link.Host, decoded)
+			// a dead link must not count toward max
+			alive := decoded && (link.Probe == nil || link.Probe.Alive)
+			if alive {
+				endRes = append(endRes, link)
+			}
+			if done(link.Index, alive) {
+				pending = 0
+			}
+		}
+	}
+
+	s.client.ranking.sort(endRes)
+	if len(endRes) > max {
+		endRes = endRes[:max]
+	}
+	return endRes, nil
+}
+
+func (s *EpisodeService) resolveLink(ctx context.Context, animeName string, episodeNb int, link string, index int) DownloadInfo {
+	ctx, cancel := context.WithTimeout(ctx, s.client.decodeTimeout)
+	defer cancel()
+
+	info := DownloadInfo{
+		EpisodeHostLink: link,
+		Host:            linkHost(link),
+		Index:           index,
+	}
+	start := time.Now()
+	url, err := s.client.resolvers.Resolve(ctx, link)
+	info.ResolveDuration = time.Since(start)
+	if err != nil && ctx.Err() == context.Canceled {
+		// enough links were found or the caller gave up
+		return info
+	}
+	if err != nil {
+		s.client.logger.WarnContext(ctx, "decoding link failed",
+			slog.String("anime", animeName),
+			slog.Int("episode", episodeNb),
+			slog.String("host", info.Host),
+			slog.Any("error", err))
+	}
+	if err != nil {
+		return info
+	}
+	info.EpisodeDirectDownloadLink = url
+	info.Label = qualityLabel(url)
+	if s.client.probe {
+		// a failed probe leaves info.Probe.Alive false
+		info, _ = s.ProbeDownloadInfoWithContext(ctx, info)
+	}
+	return info
+}
+
+// tracker returns a function recording that the link at index settled and
+// reporting whether enough links were resolved for the stop mode.
+func (m stopMode) tracker(n, max int) func(index int, resolved bool) bool {
+	switch m {
+	case stopFirstResolved:
+		count := 0
+		return func(_ int, resolved bool) bool {
+			if resolved {
+				count++
+			}
+			return count >= max
+		}
+	case stopInOrder:
+		settled := make([]bool, n)
+		ok := make([]bool, n)
+		prefix, count := 0, 0
+		return func(index int, resolved bool) bool {
+			settled[index], ok[index] = true, resolved
+			for prefix < n && settled[prefix] {
+				if ok[prefix] {
+					count++
+				}
+				prefix++
+			}
+			return count >= max
+		}
+	default:
+		return func(int, bool) bool { return false }
+	}
+}
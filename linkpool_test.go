@@ -0,0 +1,166 @@
+package tohru
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingResolver resolves links of fast.example at once and blocks on
+// ctx for any other host, counting the calls still running.
+type blockingResolver struct {
+	running atomic.Int32
+}
+
+func (r *blockingResolver) CanResolve(string) bool { return true }
+
+func (r *blockingResolver) Resolve(ctx context.Context, link string) (string, error) {
+	r.running.Add(1)
+	defer r.running.Add(-1)
+	if strings.Contains(link, "fast.example") {
+		return link + "/720p.mp4", nil
+	}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func newLinksClient(t *testing.T, links DownloadLinks, res *blockingResolver, opts ...Option) *TohruClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(links)
+	}))
+	t.Cleanup(srv.Close)
+	return NewTohruClient(NewConfig("id", "secret", ""), append([]Option{
+		WithBaseURL(srv.URL),
+		WithLinkResolvers(res),
+		WithDecodeConcurrency(2),
+		WithDecodeTimeout(time.Minute),
+	}, opts...)...)
+}
+
+// poolGoroutines counts the goroutines started by resolveLinks still alive.
+func poolGoroutines() int {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	n := 0
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(g, "created by github.com/khatibomar/tohru.(*EpisodeService).resolveLinks") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestGetFirstDirectDownloadInfoStopsWorkers(t *testing.T) {
+	res := &blockingResolver{}
+	c := newLinksClient(t, DownloadLinks{
+		"https://fast.example/a",
+		"https://slow.example/b",
+		"https://slow.example/c",
+		"https://slow.example/d",
+	}, res)
+
+	done := make(chan struct{})
+	var info DownloadInfo
+	var err error
+	go func() {
+		defer close(done)
+		info, err = c.EpisodeService.GetFirstDirectDownloadInfo("anime", 1)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetFirstDirectDownloadInfo waited for the blocked links")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.EpisodeHostLink != "https://fast.example/a" {
+		t.Errorf("got %q, want the first link", info.EpisodeHostLink)
+	}
+	if n := poolGoroutines(); n != 0 {
+		t.Errorf("%d resolveLinks goroutines still running", n)
+	}
+	if n := res.running.Load(); n != 0 {
+		t.Errorf("%d resolver calls still running", n)
+	}
+}
+
+func TestResolveLinksCancelled(t *testing.T) {
+	res := &blockingResolver{}
+	c := newLinksClient(t, DownloadLinks{
+		"https://slow.example/a",
+		"https://slow.example/b",
+		"https://slow.example/c",
+	}, res)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := c.EpisodeService.GetFirstDirectDownloadInfoWithContext(ctx, "anime", 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("returned %v after the cancellation", d)
+	}
+	if n := poolGoroutines(); n != 0 {
+		t.Errorf("%d resolveLinks goroutines still running", n)
+	}
+	if n := res.running.Load(); n != 0 {
+		t.Errorf("%d resolver calls still running", n)
+	}
+}
+
+func TestEarlyStopCountsEveryLink(t *testing.T) {
+	metrics := NewInMemoryMetrics()
+	c := newLinksClient(t, DownloadLinks{
+		"https://fast.example/a",
+		"https://slow.example/b",
+		"https://slow.example/c",
+		"https://slow.example/d",
+	}, &blockingResolver{}, WithMetrics(metrics))
+	if _, err := c.EpisodeService.GetFirstDirectDownloadInfo("anime", 1); err != nil {
+		t.Fatal(err)
+	}
+	links := metrics.Links()
+	if got := links["fast.example"]; got != (LinkMetrics{Returned: 1, Decoded: 1}) {
+		t.Errorf("fast.example: got %+v, want 1 returned and decoded", got)
+	}
+	if got := links["slow.example"]; got != (LinkMetrics{Returned: 3}) {
+		t.Errorf("slow.example: got %+v, want 3 returned, none decoded", got)
+	}
+}
+
+func TestProbingSkipsDeadLinks(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "dead") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "video/mp4")
+	}))
+	defer files.Close()
+
+	c := newLinksClient(t, DownloadLinks{
+		files.URL + "/fast.example/dead",
+		files.URL + "/fast.example/alive",
+	}, &blockingResolver{}, WithDecodeConcurrency(1), WithLinkProbing(true))
+	info, err := c.EpisodeService.GetFirstDirectDownloadInfo("anime", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := files.URL + "/fast.example/alive/720p.mp4"; info.EpisodeDirectDownloadLink != want {
+		t.Errorf("got %q, want %q", info.EpisodeDirectDownloadLink, want)
+	}
+	if info.Probe == nil || !info.Probe.Alive {
+		t.Errorf("got probe %+v, want alive", info.Probe)
+	}
+}
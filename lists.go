@@ -0,0 +1,261 @@
+package tohru
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// The user lists, CustomList, AnimeList, Favoirtes, PlanToWatch, Watched,
+// Dropped, OnHold and WatchedHistory, belong to an Anslayer account. They
+// need a user session the client does not support and have no method.
+// Filter and LatestUpdatedEpisodeNew are served by SearchByName, Query and
+// GetLatestAnimes.
+
+// DefaultOrder returns the order used by the dedicated list methods.
+// Top lists are ordered by rating, except TopUpcoming whose animes are not
+// rated before airing.
+func DefaultOrder(l listType) order {
+	switch l {
+	case TopAnime, TopTv, TopMovie, TopCurrentlyAiring,
+		TopAnimeMal, TopTvMal, CurrentlyAiringMal:
+		return RatingDesc
+	default:
+		return LatestFirst
+	}
+}
+
+// GetList returns a page of the list l ordered by orderBy,
+// most lists are better served by their dedicated method.
+func (s *AnimeService) GetList(l listType, orderBy order, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(context.Background(), l, orderBy, offset, limit)
+}
+
+func (s *AnimeService) GetListWithContext(ctx context.Context, l listType, orderBy order, offset, limit int) ([]Anime, error) {
+	payload, err := listPayload(l, orderBy, offset, limit)
+	if err != nil {
+		return []Anime{}, err
+	}
+	return s.getAnimeList(ctx, payload)
+}
+
+// ListPager walks the list l in its default order.
+func (s *AnimeService) ListPager(l listType, limit int) *Pager[Anime] {
+	return s.pager(limit, func(offset, limit int) (JsonPayload, error) {
+		return listPayload(l, DefaultOrder(l), offset, limit)
+	})
+}
+
+func listPayload(l listType, orderBy order, offset, limit int) (JsonPayload, error) {
+	payload := make(JsonPayload)
+	var err error
+
+	err = payload.WithOffset(offset)
+	if err != nil {
+		return nil, err
+	}
+	err = payload.WithLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	err = payload.WithOrder(orderBy)
+	if err != nil {
+		return nil, err
+	}
+	err = payload.WithListType(l)
+	if err != nil {
+		return nil, err
+	}
+	err = payload.WithJustInfo("Yes")
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// GetTopAnime returns the highest rated animes.
+func (s *AnimeService) GetTopAnime(offset, limit int) ([]Anime, error) {
+	return s.GetTopAnimeWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetTopAnimeWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, TopAnime, DefaultOrder(TopAnime), offset, limit)
+}
+
+// GetTopTv returns the highest rated TV series.
+func (s *AnimeService) GetTopTv(offset, limit int) ([]Anime, error) {
+	return s.GetTopTvWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetTopTvWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, TopTv, DefaultOrder(TopTv), offset, limit)
+}
+
+// GetTopMovies returns the highest rated movies.
+func (s *AnimeService) GetTopMovies(offset, limit int) ([]Anime, error) {
+	return s.GetTopMoviesWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetTopMoviesWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, TopMovie, DefaultOrder(TopMovie), offset, limit)
+}
+
+// GetTopCurrentlyAiring returns the highest rated animes currently airing.
+func (s *AnimeService) GetTopCurrentlyAiring(offset, limit int) ([]Anime, error) {
+	return s.GetTopCurrentlyAiringWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetTopCurrentlyAiringWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, TopCurrentlyAiring, DefaultOrder(TopCurrentlyAiring), offset, limit)
+}
+
+// GetTopUpcoming returns the most anticipated animes not aired yet.
+func (s *AnimeService) GetTopUpcoming(offset, limit int) ([]Anime, error) {
+	return s.GetTopUpcomingWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetTopUpcomingWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, TopUpcoming, DefaultOrder(TopUpcoming), offset, limit)
+}
+
+// GetTopAnimeMal returns the highest rated animes on MyAnimeList.
+func (s *AnimeService) GetTopAnimeMal(offset, limit int) ([]Anime, error) {
+	return s.GetTopAnimeMalWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetTopAnimeMalWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, TopAnimeMal, DefaultOrder(TopAnimeMal), offset, limit)
+}
+
+// GetTopTvMal returns the highest rated TV series on MyAnimeList.
+func (s *AnimeService) GetTopTvMal(offset, limit int) ([]Anime, error) {
+	return s.GetTopTvMalWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetTopTvMalWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, TopTvMal, DefaultOrder(TopTvMal), offset, limit)
+}
+
+// GetTopCurrentlyAiringMal returns the highest rated airing animes on MyAnimeList.
+func (s *AnimeService) GetTopCurrentlyAiringMal(offset, limit int) ([]Anime, error) {
+	return s.GetTopCurrentlyAiringMalWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetTopCurrentlyAiringMalWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, CurrentlyAiringMal, DefaultOrder(CurrentlyAiringMal), offset, limit)
+}
+
+// GetCurrentlyAiring returns the animes currently airing.
+func (s *AnimeService) GetCurrentlyAiring(offset, limit int) ([]Anime, error) {
+	return s.GetCurrentlyAiringWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetCurrentlyAiringWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, CurrentlyAiring, DefaultOrder(CurrentlyAiring), offset, limit)
+}
+
+// GetFeatured returns the animes featured on Anslayer home page.
+func (s *AnimeService) GetFeatured(offset, limit int) ([]Anime, error) {
+	return s.GetFeaturedWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetFeaturedWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, Featured, DefaultOrder(Featured), offset, limit)
+}
+
+// GetLastAddedTv returns the last TV series added to Anslayer.
+func (s *AnimeService) GetLastAddedTv(offset, limit int) ([]Anime, error) {
+	return s.GetLastAddedTvWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetLastAddedTvWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, LastAddedTv, DefaultOrder(LastAddedTv), offset, limit)
+}
+
+// GetLastAddedMovies returns the last movies added to Anslayer.
+func (s *AnimeService) GetLastAddedMovies(offset, limit int) ([]Anime, error) {
+	return s.GetLastAddedMoviesWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetLastAddedMoviesWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, LastAddedMovie, DefaultOrder(LastAddedMovie), offset, limit)
+}
+
+// GetLatestUpdatedEpisodes returns the animes with the latest published episodes.
+func (s *AnimeService) GetLatestUpdatedEpisodes(offset, limit int) ([]Anime, error) {
+	return s.GetLatestUpdatedEpisodesWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetLatestUpdatedEpisodesWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, LatestUpdatedEpisode, DefaultOrder(LatestUpdatedEpisode), offset, limit)
+}
+
+// GetSchedule returns the animes airing this week, see GroupByReleaseDay.
+func (s *AnimeService) GetSchedule(offset, limit int) ([]Anime, error) {
+	return s.GetScheduleWithContext(context.Background(), offset, limit)
+}
+
+func (s *AnimeService) GetScheduleWithContext(ctx context.Context, offset, limit int) ([]Anime, error) {
+	return s.GetListWithContext(ctx, Schedule, DefaultOrder(Schedule), offset, limit)
+}
+
+type charactersEndRes struct {
+	Response struct {
+		Data []Character `json:"data"`
+	} `json:"response"`
+}
+
+// Character is an anime character returned by the AnimeCharacters list.
+// The field names follow the naming of the other list responses and were not
+// checked against a live response, Raw keeps the whole object for the fields
+// they miss.
+type Character struct {
+	CharacterID       string          `json:"character_id"`
+	CharacterName     string          `json:"character_name"`
+	CharacterRole     string          `json:"character_role"`
+	CharacterImageURL string          `json:"character_image_url"`
+	VoiceActorName    string          `json:"voice_actor_name"`
+	Raw               json.RawMessage `json:"-"`
+}
+
+func (c *Character) UnmarshalJSON(data []byte) error {
+	type character Character
+	var v character
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = Character(v)
+	c.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// GetAnimeCharacters returns the characters of the anime.
+func (s *AnimeService) GetAnimeCharacters(animeID, offset, limit int) ([]Character, error) {
+	return s.GetAnimeCharactersWithContext(context.Background(), animeID, offset, limit)
+}
+
+func (s *AnimeService) GetAnimeCharactersWithContext(ctx context.Context, animeID, offset, limit int) ([]Character, error) {
+	payload, err := listPayload(AnimeCharacters, DefaultOrder(AnimeCharacters), offset, limit)
+	if err != nil {
+		return []Character{}, err
+	}
+	err = payload.WithAnimeId(animeID)
+	if err != nil {
+		return []Character{}, err
+	}
+
+	var characters charactersEndRes
+	if err := s.getPublished(ctx, payload, &characters); err != nil {
+		return []Character{}, err
+	}
+	return characters.Response.Data, nil
+}
+
+// GroupByReleaseDay groups animes, usually returned by GetSchedule,
+// by their AnimeReleaseDay.
+func GroupByReleaseDay(animes []Anime) map[string][]Anime {
+	days := make(map[string][]Anime)
+	for _, a := range animes {
+		days[a.AnimeReleaseDay] = append(days[a.AnimeReleaseDay], a)
+	}
+	return days
+}
@@ -0,0 +1,160 @@
+package tohru
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// listServer serves testdata/lists/<list_type>.json and records the last payload.
+type listServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	payload JsonPayload
+}
+
+func newListServer(t *testing.T) *listServer {
+	t.Helper()
+	ls := &listServer{}
+	ls.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload JsonPayload
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("json")), &payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ls.mu.Lock()
+		ls.payload = payload
+		ls.mu.Unlock()
+
+		listType, _ := payload["list_type"].(string)
+		data, err := os.ReadFile(filepath.Join("testdata", "lists", listType+".json"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(ls.Close)
+	return ls
+}
+
+func (ls *listServer) last() JsonPayload {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.payload
+}
+
+func TestListMethods(t *testing.T) {
+	ls := newListServer(t)
+	s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(ls.URL)).AnimeService
+
+	airing := func(a Anime) bool { return a.AnimeStatus == string(CurrentlyAiringStatus) && a.AnimeReleaseDay != "" }
+	movie := func(a Anime) bool { return a.AnimeType == string(Movie) }
+	tv := func(a Anime) bool { return a.AnimeType == string(TV) }
+	rated := func(a Anime) bool { return a.AnimeRating != "" }
+	tests := []struct {
+		name     string
+		get      func(offset, limit int) ([]Anime, error)
+		listType listType
+		order    order
+		first    string
+		each     func(Anime) bool
+	}{
+		{"GetTopAnime", s.GetTopAnime, TopAnime, RatingDesc, "Fullmetal Alchemist: Brotherhood", rated},
+		{"GetTopTv", s.GetTopTv, TopTv, RatingDesc, "Fullmetal Alchemist: Brotherhood", tv},
+		{"GetTopMovies", s.GetTopMovies, TopMovie, RatingDesc, "Kimi no Na wa.", movie},
+		{"GetTopCurrentlyAiring", s.GetTopCurrentlyAiring, TopCurrentlyAiring, RatingDesc, "One Piece", airing},
+		{"GetTopUpcoming", s.GetTopUpcoming, TopUpcoming, LatestFirst, "Chainsaw Man Movie: Reze-hen", func(a Anime) bool {
+			return a.AnimeStatus == string(NotYetAired) && a.AnimeRating == ""
+		}},
+		{"GetTopAnimeMal", s.GetTopAnimeMal, TopAnimeMal, RatingDesc, "Fullmetal Alchemist: Brotherhood", rated},
+		{"GetTopTvMal", s.GetTopTvMal, TopTvMal, RatingDesc, "Fullmetal Alchemist: Brotherhood", tv},
+		{"GetTopCurrentlyAiringMal", s.GetTopCurrentlyAiringMal, CurrentlyAiringMal, RatingDesc, "One Piece", airing},
+		{"GetCurrentlyAiring", s.GetCurrentlyAiring, CurrentlyAiring, LatestFirst, "One Piece", airing},
+		{"GetFeatured", s.GetFeatured, Featured, LatestFirst, "Dungeon Meshi", rated},
+		{"GetLastAddedTv", s.GetLastAddedTv, LastAddedTv, LatestFirst, "Solo Leveling", tv},
+		{"GetLastAddedMovies", s.GetLastAddedMovies, LastAddedMovie, LatestFirst, "Kimitachi wa Dou Ikiru ka", movie},
+		{"GetLatestUpdatedEpisodes", s.GetLatestUpdatedEpisodes, LatestUpdatedEpisode, LatestFirst, "One Piece", func(a Anime) bool {
+			return a.LatestEpisodeID != "" && a.LatestEpisodeName != ""
+		}},
+		{"GetSchedule", s.GetSchedule, Schedule, LatestFirst, "Dungeon Meshi", airing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			animes, err := tt.get(4, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(animes) != 2 || animes[0].AnimeName != tt.first {
+				t.Fatalf("unexpected animes %+v", animes)
+			}
+			for _, a := range animes {
+				if a.AnimeID == "" || a.AnimeCoverImageURL == "" || !tt.each(a) {
+					t.Errorf("unexpected anime in %s: %+v", tt.listType, a)
+				}
+			}
+
+			payload := ls.last()
+			if payload["list_type"] != string(tt.listType) {
+				t.Errorf("list_type = %v, want %s", payload["list_type"], tt.listType)
+			}
+			if payload["_order_by"] != string(tt.order) {
+				t.Errorf("_order_by = %v, want %s", payload["_order_by"], tt.order)
+			}
+			if payload["_offset"] != float64(4) || payload["_limit"] != float64(2) {
+				t.Errorf("_offset, _limit = %v, %v, want 4, 2", payload["_offset"], payload["_limit"])
+			}
+		})
+	}
+}
+
+func TestGetAnimeCharacters(t *testing.T) {
+	ls := newListServer(t)
+	s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(ls.URL)).AnimeService
+
+	characters, err := s.GetAnimeCharacters(42, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(characters) != 2 {
+		t.Fatalf("got %d characters, want 2", len(characters))
+	}
+	got := characters[0]
+	if got.CharacterID != "1" || got.CharacterName != "Tohru" || got.CharacterRole != "Main" ||
+		got.CharacterImageURL != "https://anslayer.com/characters/1.jpg" || got.VoiceActorName != "Yuki Kuwahara" {
+		t.Errorf("unexpected character %+v", got)
+	}
+	var raw map[string]string
+	if err := json.Unmarshal(got.Raw, &raw); err != nil || raw["character_name"] != "Tohru" {
+		t.Errorf("Raw = %s, want the whole character object", got.Raw)
+	}
+
+	payload := ls.last()
+	if payload["list_type"] != string(AnimeCharacters) {
+		t.Errorf("list_type = %v, want %s", payload["list_type"], AnimeCharacters)
+	}
+	if payload["_order_by"] != string(LatestFirst) {
+		t.Errorf("_order_by = %v, want %s", payload["_order_by"], LatestFirst)
+	}
+	if payload["anime_id"] != float64(42) {
+		t.Errorf("anime_id = %v, want 42", payload["anime_id"])
+	}
+}
+
+func TestGroupByReleaseDay(t *testing.T) {
+	ls := newListServer(t)
+	s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(ls.URL)).AnimeService
+
+	animes, err := s.GetSchedule(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	days := GroupByReleaseDay(animes)
+	if len(days["Thursday"]) != 1 || len(days["Sunday"]) != 1 {
+		t.Fatalf("unexpected schedule %v", days)
+	}
+}
@@ -0,0 +1,47 @@
+package tohru
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+)
+
+// WithLogger makes the client log requests, retries and link resolution.
+// The client is silent by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(t *TohruClient) {
+		if logger != nil {
+			t.logger = logger
+		}
+	}
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// failureLevel logs the caller giving up at Debug and API client errors,
+// like an unknown episode, at Info. Anything else is an Error.
+func failureLevel(err error) slog.Level {
+	var apiErr *APIError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return slog.LevelDebug
+	case errors.As(err, &apiErr) && apiErr.StatusCode < 500:
+		return slog.LevelInfo
+	default:
+		return slog.LevelError
+	}
+}
+
+func linkHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
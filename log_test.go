@@ -0,0 +1,54 @@
+package tohru
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestFailureLogLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		cancel bool
+		want   string
+	}{
+		{"not found", http.StatusNotFound, false, "INFO"},
+		{"server error", http.StatusInternalServerError, false, "ERROR"},
+		{"cancelled", http.StatusOK, true, "DEBUG"},
+	}
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tt.cancel {
+				// the caller gives up while the request is in flight
+				cancel()
+				<-r.Context().Done()
+				return
+			}
+			w.WriteHeader(tt.status)
+		}))
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL), WithLogger(logger))
+
+		_, _ = c.AnimeService.GetAnimeDetailsWithContext(ctx, 1)
+		cancel()
+		srv.Close()
+
+		var level string
+		for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+			var record struct{ Level, Msg string }
+			if err := json.Unmarshal(line, &record); err == nil && record.Msg == "request failed" {
+				level = record.Level
+			}
+		}
+		if level != tt.want {
+			t.Errorf("%s: logged at %q, want %q", tt.name, level, tt.want)
+		}
+	}
+}
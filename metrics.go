@@ -0,0 +1,240 @@
+package tohru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsCollector receives measurements from TohruClient,
+// implementations must be safe for concurrent use.
+type MetricsCollector interface {
+	// ObserveRequest is called once per attempt sent to the API.
+	ObserveRequest(path string, duration time.Duration, err error)
+	// ObserveLinkDecode is called once for every download link returned by
+	// the API, links left aside once enough were resolved count as not decoded.
+	ObserveLinkDecode(host string, decoded bool)
+	// ObserveBackupFallback is called once per direct links lookup.
+	ObserveBackupFallback(fallback bool)
+}
+
+func WithMetrics(m MetricsCollector) Option {
+	return func(t *TohruClient) {
+		if m != nil {
+			t.metrics = m
+		}
+	}
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRequest(string, time.Duration, error) {}
+func (nopMetrics) ObserveLinkDecode(string, bool)              {}
+func (nopMetrics) ObserveBackupFallback(bool)                  {}
+
+// ErrorKind classifies err for metrics labels.
+func ErrorKind(err error) string {
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrServer):
+		return "server"
+	case errors.As(err, &apiErr):
+		return "client"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	default:
+		return "network"
+	}
+}
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the request latency histogram.
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// InMemoryMetrics is a MetricsCollector keeping every measurement in memory.
+type InMemoryMetrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	requests  map[string]*requestMetrics
+	links     map[string]*LinkMetrics
+	lookups   int64
+	fallbacks int64
+}
+
+type requestMetrics struct {
+	count   int64
+	sum     float64
+	buckets []int64
+	errors  map[string]int64
+}
+
+// LinkMetrics counts download links of a host.
+type LinkMetrics struct {
+	Returned int64
+	Decoded  int64
+}
+
+func NewInMemoryMetrics() *InMemoryMetrics {
+	return &InMemoryMetrics{
+		buckets:  DefaultLatencyBuckets,
+		requests: make(map[string]*requestMetrics),
+		links:    make(map[string]*LinkMetrics),
+	}
+}
+
+func (m *InMemoryMetrics) ObserveRequest(path string, duration time.Duration, err error) {
+	path = "/" + strings.TrimPrefix(path, "/")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.requests[path]
+	if !ok {
+		r = &requestMetrics{
+			buckets: make([]int64, len(m.buckets)),
+			errors:  make(map[string]int64),
+		}
+		m.requests[path] = r
+	}
+	secs := duration.Seconds()
+	r.count++
+	r.sum += secs
+	for i, le := range m.buckets {
+		if secs <= le {
+			r.buckets[i]++
+		}
+	}
+	if kind := ErrorKind(err); kind != "" {
+		r.errors[kind]++
+	}
+}
+
+func (m *InMemoryMetrics) ObserveLinkDecode(host string, decoded bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.links[host]
+	if !ok {
+		l = &LinkMetrics{}
+		m.links[host] = l
+	}
+	l.Returned++
+	if decoded {
+		l.Decoded++
+	}
+}
+
+func (m *InMemoryMetrics) ObserveBackupFallback(fallback bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups++
+	if fallback {
+		m.fallbacks++
+	}
+}
+
+// Links returns the link counters per host.
+func (m *InMemoryMetrics) Links() map[string]LinkMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	links := make(map[string]LinkMetrics, len(m.links))
+	for host, l := range m.links {
+		links[host] = *l
+	}
+	return links
+}
+
+// BackupFallbackRate returns the ratio of lookups that fell back to backup links.
+func (m *InMemoryMetrics) BackupFallbackRate() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lookups == 0 {
+		return 0
+	}
+	return float64(m.fallbacks) / float64(m.lookups)
+}
+
+// WritePrometheus renders the metrics in the Prometheus text exposition format.
+func (m *InMemoryMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	b.WriteString("# HELP tohru_requests_total Requests sent to the Anslayer API.\n")
+	b.WriteString("# TYPE tohru_requests_total counter\n")
+	paths := sortedKeys(m.requests)
+	for _, path := range paths {
+		fmt.Fprintf(&b, "tohru_requests_total{path=%q} %d\n", path, m.requests[path].count)
+	}
+
+	b.WriteString("# HELP tohru_request_errors_total Failed requests by error kind.\n")
+	b.WriteString("# TYPE tohru_request_errors_total counter\n")
+	for _, path := range paths {
+		r := m.requests[path]
+		for _, kind := range sortedKeys(r.errors) {
+			fmt.Fprintf(&b, "tohru_request_errors_total{path=%q,kind=%q} %d\n", path, kind, r.errors[kind])
+		}
+	}
+
+	b.WriteString("# HELP tohru_request_duration_seconds Request latency.\n")
+	b.WriteString("# TYPE tohru_request_duration_seconds histogram\n")
+	for _, path := range paths {
+		r := m.requests[path]
+		for i, le := range m.buckets {
+			fmt.Fprintf(&b, "tohru_request_duration_seconds_bucket{path=%q,le=\"%g\"} %d\n", path, le, r.buckets[i])
+		}
+		fmt.Fprintf(&b, "tohru_request_duration_seconds_bucket{path=%q,le=\"+Inf\"} %d\n", path, r.count)
+		fmt.Fprintf(&b, "tohru_request_duration_seconds_sum{path=%q} %g\n", path, r.sum)
+		fmt.Fprintf(&b, "tohru_request_duration_seconds_count{path=%q} %d\n", path, r.count)
+	}
+
+	b.WriteString("# HELP tohru_download_links_total Download links returned by the API.\n")
+	b.WriteString("# TYPE tohru_download_links_total counter\n")
+	hosts := sortedKeys(m.links)
+	for _, host := range hosts {
+		fmt.Fprintf(&b, "tohru_download_links_total{host=%q} %d\n", host, m.links[host].Returned)
+	}
+	b.WriteString("# HELP tohru_download_links_decoded_total Download links decoded to a direct link.\n")
+	b.WriteString("# TYPE tohru_download_links_decoded_total counter\n")
+	for _, host := range hosts {
+		fmt.Fprintf(&b, "tohru_download_links_decoded_total{host=%q} %d\n", host, m.links[host].Decoded)
+	}
+
+	b.WriteString("# HELP tohru_link_lookups_total Direct links lookups.\n")
+	b.WriteString("# TYPE tohru_link_lookups_total counter\n")
+	fmt.Fprintf(&b, "tohru_link_lookups_total %d\n", m.lookups)
+	b.WriteString("# HELP tohru_backup_fallbacks_total Lookups that fell back to backup links.\n")
+	b.WriteString("# TYPE tohru_backup_fallbacks_total counter\n")
+	fmt.Fprintf(&b, "tohru_backup_fallbacks_total %d\n", m.fallbacks)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PrometheusHandler serves m in the Prometheus text exposition format.
+func PrometheusHandler(m *InMemoryMetrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = m.WritePrometheus(w)
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,92 @@
+package tohru
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+)
+
+// Middleware wraps the transport used for every request sent to the API.
+// Requests to file hosts, such as probes and downloads, skip it.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// WithMiddleware adds middlewares to the API requests, the first one is the
+// outermost. File host requests are left out.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(t *TohruClient) {
+		t.middlewares = append(t.middlewares, mws...)
+	}
+}
+
+// Observe builds a Middleware calling fn once every request completes.
+func Observe(fn func(req *http.Request, res *http.Response, duration time.Duration, err error)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.RoundTrip(req)
+			fn(req, res, time.Since(start), err)
+			return res, err
+		})
+	}
+}
+
+// LoggingMiddleware logs every request with its status and duration.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return Observe(func(req *http.Request, res *http.Response, duration time.Duration, err error) {
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("path", req.URL.Path),
+			slog.Duration("duration", duration),
+		}
+		if err != nil {
+			logger.LogAttrs(req.Context(), slog.LevelError, "request failed", append(attrs, slog.Any("error", err))...)
+			return
+		}
+		logger.LogAttrs(req.Context(), slog.LevelInfo, "request completed", append(attrs, slog.Int("status", res.StatusCode))...)
+	})
+}
+
+// DumpMiddleware writes every request and response to w,
+// bodies are included when body is true.
+func DumpMiddleware(w io.Writer, body bool) Middleware {
+	var mu sync.Mutex
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if dump, err := httputil.DumpRequestOut(req, body); err == nil {
+				mu.Lock()
+				_, _ = w.Write(append(dump, '\n'))
+				mu.Unlock()
+			}
+			res, err := next.RoundTrip(req)
+			if err != nil {
+				return res, err
+			}
+			if dump, err := httputil.DumpResponse(res, body); err == nil {
+				mu.Lock()
+				_, _ = w.Write(append(dump, '\n'))
+				mu.Unlock()
+			}
+			return res, nil
+		})
+	}
+}
+
+func chain(transport http.RoundTripper, mws []Middleware) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(mws) - 1; i >= 0; i-- {
+		transport = mws[i](transport)
+	}
+	return transport
+}
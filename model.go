@@ -0,0 +1,215 @@
+package tohru
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypedAnime is Anime with its fields parsed, Raw keeps the server strings.
+type TypedAnime struct {
+	Raw               Anime
+	ID                int
+	Name              string
+	Type              animeType
+	Status            status
+	Season            season
+	ReleaseYear       int
+	Rating            float64
+	LatestEpisodeID   int
+	LatestEpisodeName string
+	Genres            []string
+	CoverImageURL     string
+	TrailerURL        string
+	ReleaseDay        string
+}
+
+// TypedAnimeDetails is AnimeDetails with its fields parsed, Raw keeps the server strings.
+type TypedAnimeDetails struct {
+	Raw             AnimeDetails
+	ID              int
+	Name            string
+	EnglishTitle    string
+	Description     string
+	Type            animeType
+	Status          status
+	Season          season
+	ReleaseYear     int
+	AgeRating       string
+	Rating          float64
+	RatingUserCount int
+	Keywords        []string
+	Genres          []Genre
+	CoverImageURL   string
+	TrailerURL      string
+	ReleaseDay      string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	Score           float64
+	ScoredBy        int
+	Source          string
+	Episodes        int
+	EpisodeDuration time.Duration
+	AiredFrom       time.Time
+	AiredTo         time.Time
+	Studios         []Studio
+}
+
+// Typed parses a, the returned error lists every field that failed to parse
+// and the matching fields are left to their zero value.
+func (a Anime) Typed() (TypedAnime, error) {
+	var p fieldParser
+	return TypedAnime{
+		Raw:               a,
+		ID:                p.int("anime_id", a.AnimeID),
+		Name:              a.AnimeName,
+		Type:              animeType(a.AnimeType),
+		Status:            status(a.AnimeStatus),
+		Season:            season(a.AnimeSeason),
+		ReleaseYear:       p.int("anime_release_year", a.AnimeReleaseYear),
+		Rating:            p.float("anime_rating", a.AnimeRating),
+		LatestEpisodeID:   p.int("latest_episode_id", a.LatestEpisodeID),
+		LatestEpisodeName: a.LatestEpisodeName,
+		Genres:            splitList(a.AnimeGenres),
+		CoverImageURL:     a.AnimeCoverImageURL,
+		TrailerURL:        a.AnimeTrailerURL,
+		ReleaseDay:        a.AnimeReleaseDay,
+	}, p.err()
+}
+
+// Typed parses ad, the returned error lists every field that failed to parse
+// and the matching fields are left to their zero value.
+func (ad AnimeDetails) Typed() (TypedAnimeDetails, error) {
+	var p fieldParser
+	info := ad.MoreInfoResult
+	return TypedAnimeDetails{
+		Raw:             ad,
+		ID:              p.int("anime_id", ad.AnimeID),
+		Name:            ad.AnimeName,
+		EnglishTitle:    ad.AnimeEnglishTitle,
+		Description:     ad.AnimeDescription,
+		Type:            animeType(ad.AnimeType),
+		Status:          status(ad.AnimeStatus),
+		Season:          season(ad.AnimeSeason),
+		ReleaseYear:     p.int("anime_release_year", ad.AnimeReleaseYear),
+		AgeRating:       ad.AnimeAgeRating,
+		Rating:          p.float("anime_rating", ad.AnimeRating),
+		RatingUserCount: p.int("anime_rating_user_count", ad.AnimeRatingUserCount),
+		Keywords:        splitList(ad.AnimeKeywords),
+		Genres:          ad.Genres(),
+		CoverImageURL:   ad.AnimeCoverImageURL,
+		TrailerURL:      ad.AnimeTrailerURL,
+		ReleaseDay:      ad.AnimeReleaseDay,
+		CreatedAt:       p.time("anime_created_at", ad.AnimeCreatedAt),
+		UpdatedAt:       p.time("anime_updated_at", ad.AnimeUpdatedAt),
+		Score:           p.float("score", info.Score),
+		ScoredBy:        p.int("scored_by", info.ScoredBy),
+		Source:          info.Source,
+		Episodes:        p.int("episodes", anyString(info.Episodes)),
+		EpisodeDuration: p.duration("duration", info.Duration),
+		AiredFrom:       p.time("aired_from", info.AiredFrom),
+		AiredTo:         p.time("aired_to", anyString(info.AiredTo)),
+		Studios:         info.Studios(),
+	}, p.err()
+}
+
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+var durationPart = regexp.MustCompile(`(\d+)\s*(hr|min|sec)`)
+
+type fieldParser struct {
+	errs []error
+}
+
+func (p *fieldParser) fail(field, value string, err error) {
+	p.errs = append(p.errs, fmt.Errorf("%s %q: %w", field, value, err))
+}
+
+func (p *fieldParser) int(field, value string) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.fail(field, value, err)
+	}
+	return n
+}
+
+func (p *fieldParser) float(field, value string) float64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		p.fail(field, value, err)
+	}
+	return f
+}
+
+func (p *fieldParser) time(field, value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "0000-00-00") {
+		return time.Time{}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	p.fail(field, value, errors.New("unknown time format"))
+	return time.Time{}
+}
+
+// duration parses MyAnimeList durations like "1 hr 30 min" or "24 min per ep".
+func (p *fieldParser) duration(field, value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "Unknown") {
+		return 0
+	}
+	matches := durationPart.FindAllStringSubmatch(value, -1)
+	if len(matches) == 0 {
+		p.fail(field, value, errors.New("unknown duration format"))
+		return 0
+	}
+	var d time.Duration
+	for _, m := range matches {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "hr":
+			d += time.Duration(n) * time.Hour
+		case "min":
+			d += time.Duration(n) * time.Minute
+		case "sec":
+			d += time.Duration(n) * time.Second
+		}
+	}
+	return d
+}
+
+func (p *fieldParser) err() error {
+	return errors.Join(p.errs...)
+}
+
+// anyString converts the loosely typed values of the API to a string.
+func anyString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,55 @@
+package tohru
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures a TohruClient.
+type Option func(*TohruClient)
+
+// WithHTTPClient makes the client send requests through a copy of c.
+// WithTimeout and WithTransport apply on top of it whatever their order.
+func WithHTTPClient(c *http.Client) Option {
+	return func(t *TohruClient) {
+		if c == nil {
+			return
+		}
+		client := *c
+		t.client = &client
+	}
+}
+
+// WithBaseURL points the client at another Anslayer compatible host.
+func WithBaseURL(baseURL string) Option {
+	return func(t *TohruClient) {
+		t.baseURL = baseURL
+	}
+}
+
+// WithUserAgent sets the User-Agent sent to Anslayer and to file hosts.
+func WithUserAgent(userAgent string) Option {
+	return func(t *TohruClient) {
+		t.header.Set("User-Agent", userAgent)
+	}
+}
+
+// WithTimeout bounds API requests and link probes, downloads are only
+// bounded by their context. Zero means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(t *TohruClient) {
+		t.httpOptions = append(t.httpOptions, func(c *http.Client) {
+			c.Timeout = timeout
+		})
+	}
+}
+
+// WithTransport sends requests through transport, wrapped by the middlewares
+// for API requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(t *TohruClient) {
+		t.httpOptions = append(t.httpOptions, func(c *http.Client) {
+			c.Transport = transport
+		})
+	}
+}
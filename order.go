@@ -22,3 +22,19 @@ func (o order) valid() error {
 		return fmt.Errorf("invalid order, Please use predefined orders by package")
 	}
 }
+
+const (
+	EpisodeNumberAsc  episodeOrder = "episode_number_asc"
+	EpisodeNumberDesc episodeOrder = "episode_number_desc"
+)
+
+type episodeOrder string
+
+func (o episodeOrder) valid() error {
+	switch o {
+	case EpisodeNumberAsc, EpisodeNumberDesc:
+		return nil
+	default:
+		return fmt.Errorf("invalid episode order, Please use predefined episode orders by package")
+	}
+}
@@ -0,0 +1,126 @@
+package tohru
+
+import (
+	"context"
+	"iter"
+	"strconv"
+)
+
+// pageFunc returns the items of the page at offset and the number of items
+// the server returned, which may be more than len(items) when filtering.
+type pageFunc[T any] func(ctx context.Context, offset, limit int) ([]T, MetaData, int, error)
+
+// Pager fetches a list page by page until the server returns a page shorter
+// than the limit it echoes in meta_data, or an empty page when it echoes none.
+//
+//	p := client.AnimeService.LatestAnimesPager(20)
+//	for p.Next(ctx) {
+//		for _, anime := range p.Page() {
+//			...
+//		}
+//	}
+//	if err := p.Err(); err != nil {
+//		...
+//	}
+type Pager[T any] struct {
+	fetch  pageFunc[T]
+	offset int
+	limit  int
+	page   []T
+	meta   MetaData
+	err    error
+	done   bool
+}
+
+func newPager[T any](limit int, fetch pageFunc[T]) *Pager[T] {
+	return &Pager[T]{fetch: fetch, limit: limit}
+}
+
+// Next fetches the next page, it returns false when there are no more
+// pages or an error occurred.
+func (p *Pager[T]) Next(ctx context.Context) bool {
+	if p.done || p.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	for {
+		page, meta, fetched, err := p.fetch(ctx, p.offset, p.limit)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.page = page
+		p.meta = meta
+		p.offset += fetched
+		if p.lastPage(fetched, meta) {
+			p.done = true
+		}
+		if len(page) > 0 {
+			return true
+		}
+		if p.done {
+			return false
+		}
+		if err := ctx.Err(); err != nil {
+			p.err = err
+			return false
+		}
+	}
+}
+
+// lastPage reports whether a page of fetched items ends the list. The server
+// may cap the requested limit, so a short page only ends it when shorter than
+// the echoed limit.
+func (p *Pager[T]) lastPage(fetched int, meta MetaData) bool {
+	switch {
+	case fetched == 0:
+		return true
+	case fetched > p.limit:
+		// the server ignored the limit and sent everything
+		return true
+	case fetched == p.limit:
+		return false
+	}
+	served, err := strconv.Atoi(meta.Limit)
+	if err != nil || served <= 0 {
+		// no echoed limit, only an empty page ends the list
+		return false
+	}
+	return fetched < min(served, p.limit)
+}
+
+// Page returns the page fetched by the last call to Next.
+func (p *Pager[T]) Page() []T {
+	return p.page
+}
+
+// MetaData returns the meta_data of the last fetched page.
+func (p *Pager[T]) MetaData() MetaData {
+	return p.meta
+}
+
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// All iterates over every item of the remaining pages,
+// an error is yielded once as the last element.
+func (p *Pager[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for p.Next(ctx) {
+			for _, item := range p.page {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+		if p.err != nil {
+			var zero T
+			yield(zero, p.err)
+		}
+	}
+}
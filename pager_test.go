@@ -0,0 +1,104 @@
+package tohru
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// animePagesServer serves total animes, at most maxLimit per page. The
+// requested _limit is echoed in meta_data when echo is set.
+func animePagesServer(t *testing.T, total, maxLimit int, echo bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var payload struct {
+			Offset int `json:"_offset"`
+			Limit  int `json:"_limit"`
+		}
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("json")), &payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit := min(payload.Limit, maxLimit)
+		data := []Anime{}
+		for i := payload.Offset; i < min(payload.Offset+limit, total); i++ {
+			data = append(data, Anime{AnimeID: strconv.Itoa(i + 1)})
+		}
+		res := animeEndRes{Response: latestAnimeRespond{Data: data}}
+		if echo {
+			res.Response.MetaData = MetaData{
+				Limit:  strconv.Itoa(limit),
+				Offset: strconv.Itoa(payload.Offset),
+			}
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestAnimePager(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		maxLimit int
+		echo     bool
+		calls    int32
+	}{
+		{"last page short", 5, 100, true, 2},
+		{"last page full", 4, 100, true, 2},
+		{"server caps the limit", 5, 2, true, 3},
+		{"server caps the limit silently", 5, 2, false, 4},
+		{"empty list", 0, 100, true, 1},
+	}
+	for _, tt := range tests {
+		srv, calls := animePagesServer(t, tt.total, tt.maxLimit, tt.echo)
+		c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL))
+
+		var ids []string
+		for anime, err := range c.AnimeService.LatestAnimesPager(4).All(context.Background()) {
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			ids = append(ids, anime.AnimeID)
+		}
+		if want := tt.total; len(ids) != want {
+			t.Errorf("%s: got %d animes %v, want %d", tt.name, len(ids), ids, want)
+		}
+		for i, id := range ids {
+			if id != fmt.Sprint(i+1) {
+				t.Errorf("%s: anime %d is %s", tt.name, i, id)
+				break
+			}
+		}
+		if got := calls.Load(); got != tt.calls {
+			t.Errorf("%s: %d requests, want %d", tt.name, got, tt.calls)
+		}
+	}
+}
+
+func TestPagerStopsOnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL))
+
+	p := c.AnimeService.LatestAnimesPager(10)
+	if p.Next(context.Background()) {
+		t.Fatal("Next succeeded on a server error")
+	}
+	if p.Err() == nil {
+		t.Fatal("Err() is nil after a server error")
+	}
+	if p.Next(context.Background()) {
+		t.Fatal("Next succeeded after an error")
+	}
+}
@@ -3,6 +3,8 @@ package tohru
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 type JsonPayload map[string]interface{}
@@ -69,6 +71,85 @@ func (p JsonPayload) WithReleaseYear(year int) error {
 	return nil
 }
 
+// WithReleaseYears filters by the inclusive year range [from, to]. The
+// endpoint has no range filter, anime_release_years takes a comma separated
+// list so the range is sent as every year in it.
+func (p JsonPayload) WithReleaseYears(from, to int) error {
+	if from <= 0 || to <= 0 {
+		return fmt.Errorf("year must be positive")
+	}
+	if from > to {
+		return fmt.Errorf("year range start must not be after its end")
+	}
+	years := make([]int, 0, to-from+1)
+	for y := from; y <= to; y++ {
+		years = append(years, y)
+	}
+	p["anime_release_years"] = joinInts(years)
+	return nil
+}
+
+// The filters below are named after the fields of the anime details
+// response (anime_type, anime_status, anime_genre_ids, anime_studio_ids and
+// anime_age_rating), like the anime_season and anime_release_years filters
+// the published animes endpoint already takes. Id lists are comma separated
+// as in those responses.
+
+func (p JsonPayload) WithType(t animeType) error {
+	if err := t.valid(); err != nil {
+		return err
+	}
+	p["anime_type"] = string(t)
+	return nil
+}
+
+func (p JsonPayload) WithStatus(s status) error {
+	if err := s.valid(); err != nil {
+		return err
+	}
+	p["anime_status"] = string(s)
+	return nil
+}
+
+func (p JsonPayload) WithGenres(ids ...int) error {
+	for _, id := range ids {
+		if id <= 0 {
+			return fmt.Errorf("genre id must be positive")
+		}
+	}
+	p["anime_genre_ids"] = joinInts(ids)
+	return nil
+}
+
+// WithGenreNames filters by genres using their English or Arabic names,
+// looked up in the built-in genre table. AnimeQuery.GenreNames also knows
+// the genres learned by the client.
+func (p JsonPayload) WithGenreNames(names ...string) error {
+	ids, err := seedGenres.ids(names)
+	if err != nil {
+		return err
+	}
+	return p.WithGenres(ids...)
+}
+
+func (p JsonPayload) WithStudios(ids ...int) error {
+	for _, id := range ids {
+		if id <= 0 {
+			return fmt.Errorf("studio id must be positive")
+		}
+	}
+	p["anime_studio_ids"] = joinInts(ids)
+	return nil
+}
+
+func (p JsonPayload) WithAgeRating(rating string) error {
+	if rating == "" {
+		return fmt.Errorf("age rating must not be empty")
+	}
+	p["anime_age_rating"] = rating
+	return nil
+}
+
 func (p JsonPayload) WithAnimeId(id int) error {
 	if id <= 0 {
 		return fmt.Errorf("anime id must be positive")
@@ -85,7 +166,43 @@ func (p JsonPayload) WithEpisodeId(id int) error {
 	return nil
 }
 
+func (p JsonPayload) WithEpisodeOrder(o episodeOrder) error {
+	if err := o.valid(); err != nil {
+		return err
+	}
+	p["_order_by"] = string(o)
+	return nil
+}
+
+func (p JsonPayload) WithEpisodeRange(from, to int) error {
+	if from <= 0 || to <= 0 {
+		return fmt.Errorf("episode number must be positive")
+	}
+	if from > to {
+		return fmt.Errorf("episode range start must not be after its end")
+	}
+	p["episode_number_from"] = from
+	p["episode_number_to"] = to
+	return nil
+}
+
+func (p JsonPayload) WithSinceEpisodeId(id int) error {
+	if id <= 0 {
+		return fmt.Errorf("episode id must be positive")
+	}
+	p["since_episode_id"] = id
+	return nil
+}
+
 func (p JsonPayload) ToJson() (string, error) {
 	json, err := json.Marshal(p)
 	return string(json), err
 }
+
+func joinInts(ints []int) string {
+	strs := make([]string, len(ints))
+	for i, n := range ints {
+		strs[i] = strconv.Itoa(n)
+	}
+	return strings.Join(strs, ",")
+}
@@ -0,0 +1,143 @@
+package tohru
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ProbeResult describes what a direct download link serves.
+type ProbeResult struct {
+	Alive         bool
+	StatusCode    int
+	ContentLength int64
+	ContentType   string
+	// AcceptRanges reports whether the download can be resumed.
+	AcceptRanges bool
+	Resolution   string
+	FileName     string
+}
+
+// WithLinkProbing makes GetDirectDownloadInfos probe every resolved link
+// and drop the dead ones, which never count toward the links wanted.
+func WithLinkProbing(enabled bool) Option {
+	return func(t *TohruClient) {
+		t.probe = enabled
+	}
+}
+
+func (s *EpisodeService) ProbeDownloadInfo(info DownloadInfo) (DownloadInfo, error) {
+	return s.ProbeDownloadInfoWithContext(context.Background(), info)
+}
+
+// ProbeDownloadInfoWithContext issues a HEAD request, falling back to a one byte
+// ranged GET when HEAD is not supported, and fills info.Probe.
+func (s *EpisodeService) ProbeDownloadInfoWithContext(ctx context.Context, info DownloadInfo) (DownloadInfo, error) {
+	res, err := s.probeRequest(ctx, http.MethodHead, info.EpisodeDirectDownloadLink)
+	if err != nil || res.StatusCode == http.StatusMethodNotAllowed || res.StatusCode == http.StatusNotImplemented {
+		res, err = s.probeRequest(ctx, http.MethodGet, info.EpisodeDirectDownloadLink)
+	}
+	if err != nil {
+		info.Probe = &ProbeResult{}
+		return info, err
+	}
+
+	probe := &ProbeResult{
+		StatusCode:    res.StatusCode,
+		ContentLength: res.ContentLength,
+		ContentType:   res.Header.Get("Content-Type"),
+		AcceptRanges:  res.StatusCode == http.StatusPartialContent || strings.EqualFold(res.Header.Get("Accept-Ranges"), "bytes"),
+	}
+	if res.StatusCode == http.StatusPartialContent {
+		probe.ContentLength = contentRangeTotal(res.Header.Get("Content-Range"))
+	}
+	if _, params, err := mime.ParseMediaType(res.Header.Get("Content-Disposition")); err == nil {
+		probe.FileName = params["filename"]
+	}
+	mediaType, _, _ := mime.ParseMediaType(probe.ContentType)
+	probe.Alive = (res.StatusCode == http.StatusOK || res.StatusCode == http.StatusPartialContent) &&
+		mediaType != "text/html"
+
+	probe.Resolution = info.Label
+	if probe.Resolution == "" {
+		probe.Resolution = qualityLabel(probe.FileName)
+	}
+	if probe.Resolution == "" {
+		probe.Resolution = qualityLabel(info.EpisodeDirectDownloadLink)
+	}
+	if info.Label == "" {
+		info.Label = probe.Resolution
+	}
+
+	info.Probe = probe
+	return info, nil
+}
+
+func (s *EpisodeService) probeRequest(ctx context.Context, method, link string) (*http.Response, error) {
+	req, err := s.client.newFileHostRequest(ctx, method, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	res, err := s.client.fileHost.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<10))
+	_ = res.Body.Close()
+	return res, nil
+}
+
+// ProbeDownloadInfos probes infos concurrently and returns the alive ones
+// in their original order.
+func (s *EpisodeService) ProbeDownloadInfos(infos DownloadInfos) DownloadInfos {
+	return s.ProbeDownloadInfosWithContext(context.Background(), infos)
+}
+
+func (s *EpisodeService) ProbeDownloadInfosWithContext(ctx context.Context, infos DownloadInfos) DownloadInfos {
+	probed := make(DownloadInfos, len(infos))
+	sem := make(chan struct{}, s.client.decodeConcurrency)
+	var wg sync.WaitGroup
+	for i, info := range infos {
+		wg.Add(1)
+		go func(i int, info DownloadInfo) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				probed[i] = info
+				return
+			}
+			defer func() { <-sem }()
+			probed[i], _ = s.ProbeDownloadInfoWithContext(ctx, info)
+		}(i, info)
+	}
+	wg.Wait()
+
+	alive := probed[:0]
+	for _, info := range probed {
+		if info.Probe != nil && info.Probe.Alive {
+			alive = append(alive, info)
+		}
+	}
+	return alive
+}
+
+// contentRangeTotal returns the complete length of a "bytes 0-0/1234" header.
+func contentRangeTotal(v string) int64 {
+	i := strings.LastIndex(v, "/")
+	if i < 0 {
+		return -1
+	}
+	n, err := strconv.ParseInt(v[i+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,224 @@
+package tohru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const DefaultQueryLimit = 20
+
+// AnimeQuery is a typed builder for the published animes endpoint.
+// Setters record the value and every error is reported by Validate.
+//
+//	q := tohru.NewAnimeQuery().Season(tohru.Fall).Years(2020, 2022).Type(tohru.TV)
+//	animes, err := client.AnimeService.Query(q)
+type AnimeQuery struct {
+	name       *string
+	season     season
+	yearFrom   int
+	yearTo     int
+	animeType  animeType
+	status     status
+	genres     []int
+	genreNames []string
+	studios    []int
+	ageRating  string
+	listType   listType
+	order      order
+	offset     int
+	limit      int
+}
+
+// NewAnimeQuery returns a Filter query ordered by LatestFirst.
+func NewAnimeQuery() *AnimeQuery {
+	return &AnimeQuery{
+		listType: Filter,
+		order:    LatestFirst,
+		limit:    DefaultQueryLimit,
+	}
+}
+
+func (q *AnimeQuery) Name(name string) *AnimeQuery {
+	q.name = &name
+	return q
+}
+
+func (q *AnimeQuery) Season(s season) *AnimeQuery {
+	q.season = s
+	return q
+}
+
+func (q *AnimeQuery) Year(year int) *AnimeQuery {
+	return q.Years(year, year)
+}
+
+// Years restricts the release year to the inclusive range [from, to].
+func (q *AnimeQuery) Years(from, to int) *AnimeQuery {
+	q.yearFrom, q.yearTo = from, to
+	return q
+}
+
+func (q *AnimeQuery) Type(t animeType) *AnimeQuery {
+	q.animeType = t
+	return q
+}
+
+func (q *AnimeQuery) Status(s status) *AnimeQuery {
+	q.status = s
+	return q
+}
+
+func (q *AnimeQuery) Genres(ids ...int) *AnimeQuery {
+	q.genres = append(q.genres, ids...)
+	return q
+}
+
+// GenreNames adds genres by their English or Arabic names. They are looked
+// up in the client genre catalog when the query runs, in the built-in genre
+// table by Validate, Payload and String.
+func (q *AnimeQuery) GenreNames(names ...string) *AnimeQuery {
+	q.genreNames = append(q.genreNames, names...)
+	return q
+}
+
+func (q *AnimeQuery) Studios(ids ...int) *AnimeQuery {
+	q.studios = append(q.studios, ids...)
+	return q
+}
+
+func (q *AnimeQuery) AgeRating(rating string) *AnimeQuery {
+	q.ageRating = rating
+	return q
+}
+
+func (q *AnimeQuery) ListType(l listType) *AnimeQuery {
+	q.listType = l
+	return q
+}
+
+func (q *AnimeQuery) Order(o order) *AnimeQuery {
+	q.order = o
+	return q
+}
+
+func (q *AnimeQuery) Offset(offset int) *AnimeQuery {
+	q.offset = offset
+	return q
+}
+
+func (q *AnimeQuery) Limit(limit int) *AnimeQuery {
+	q.limit = limit
+	return q
+}
+
+func (q *AnimeQuery) hasFilters() bool {
+	return q.name != nil || q.season != "" || q.yearFrom != 0 || q.yearTo != 0 ||
+		q.animeType != "" || q.status != "" || len(q.genres) > 0 || len(q.genreNames) > 0 || len(q.studios) > 0 || q.ageRating != ""
+}
+
+// Validate reports every invalid value and unsupported combination.
+func (q *AnimeQuery) Validate() error {
+	_, err := q.Payload()
+	return err
+}
+
+// Payload returns the JsonPayload sent to the API.
+func (q *AnimeQuery) Payload() (JsonPayload, error) {
+	return q.payload(seedGenres, q.offset, q.limit)
+}
+
+func (q *AnimeQuery) payload(genres *genreCatalog, offset, limit int) (JsonPayload, error) {
+	payload := make(JsonPayload)
+	var errs []error
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	check(payload.WithOffset(offset))
+	check(payload.WithLimit(limit))
+	check(payload.WithOrder(q.order))
+	check(payload.WithListType(q.listType))
+	check(payload.WithJustInfo("Yes"))
+
+	if q.hasFilters() && q.listType != Filter {
+		check(fmt.Errorf("filters can only be used with the %s list type", Filter))
+	}
+	if q.listType == Filter {
+		name := ""
+		if q.name != nil {
+			name = *q.name
+		}
+		payload.WithName(name)
+	}
+	if q.season != "" {
+		check(payload.WithSeason(q.season))
+	}
+	if q.yearFrom != 0 || q.yearTo != 0 {
+		if q.yearFrom == q.yearTo {
+			check(payload.WithReleaseYear(q.yearFrom))
+		} else {
+			check(payload.WithReleaseYears(q.yearFrom, q.yearTo))
+		}
+	}
+	if q.animeType != "" {
+		check(payload.WithType(q.animeType))
+	}
+	if q.status != "" {
+		check(payload.WithStatus(q.status))
+	}
+	if len(q.genres) > 0 || len(q.genreNames) > 0 {
+		ids, err := genres.ids(q.genreNames)
+		check(err)
+		check(payload.WithGenres(append(slices.Clone(q.genres), ids...)...))
+	}
+	if len(q.studios) > 0 {
+		check(payload.WithStudios(q.studios...))
+	}
+	if q.ageRating != "" {
+		check(payload.WithAgeRating(q.ageRating))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return payload, nil
+}
+
+// String returns the JSON sent to the API, or the validation error.
+func (q *AnimeQuery) String() string {
+	payload, err := q.Payload()
+	if err != nil {
+		return "invalid query: " + err.Error()
+	}
+	s, err := payload.ToJson()
+	if err != nil {
+		return "invalid query: " + err.Error()
+	}
+	return s
+}
+
+func (s *AnimeService) Query(q *AnimeQuery) ([]Anime, error) {
+	return s.QueryWithContext(context.Background(), q)
+}
+
+func (s *AnimeService) QueryWithContext(ctx context.Context, q *AnimeQuery) ([]Anime, error) {
+	payload, err := q.payload(s.client.genres, q.offset, q.limit)
+	if err != nil {
+		return []Anime{}, err
+	}
+	return s.getAnimeList(ctx, payload)
+}
+
+// QueryPager walks q starting at its offset, q.Limit at a time.
+func (s *AnimeService) QueryPager(q *AnimeQuery) *Pager[Anime] {
+	start := q.offset
+	p := s.pager(q.limit, func(offset, limit int) (JsonPayload, error) {
+		return q.payload(s.client.genres, offset, limit)
+	})
+	p.offset = start
+	return p
+}
@@ -0,0 +1,154 @@
+package tohru
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnimeQueryPayload(t *testing.T) {
+	q := NewAnimeQuery().
+		Name("dragon").
+		Season(Fall).
+		Years(2019, 2021).
+		Type(TV).
+		Status(FinishedAiring).
+		Genres(1, 4).
+		Studios(7).
+		AgeRating("PG-13").
+		Order(RatingDesc).
+		Offset(40).
+		Limit(20)
+
+	got, err := q.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := JsonPayload{
+		"_offset":             40,
+		"_limit":              20,
+		"_order_by":           "anime_rating_desc",
+		"list_type":           "filter",
+		"just_info":           "Yes",
+		"anime_name":          "dragon",
+		"anime_season":        "Fall",
+		"anime_release_years": "2019,2020,2021",
+		"anime_type":          "TV",
+		"anime_status":        "Finished Airing",
+		"anime_genre_ids":     "1,4",
+		"anime_studio_ids":    "7",
+		"anime_age_rating":    "PG-13",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got payload\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestAnimeQuerySingleYear(t *testing.T) {
+	got, err := NewAnimeQuery().Year(2022).Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["anime_release_years"] != 2022 {
+		t.Errorf("anime_release_years = %v, want 2022", got["anime_release_years"])
+	}
+}
+
+func TestAnimeQueryDefaults(t *testing.T) {
+	got, err := NewAnimeQuery().Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := JsonPayload{
+		"_offset":    0,
+		"_limit":     DefaultQueryLimit,
+		"_order_by":  "latest_first",
+		"list_type":  "filter",
+		"just_info":  "Yes",
+		"anime_name": "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got payload %v, want %v", got, want)
+	}
+
+	got, err = NewAnimeQuery().ListType(TopTv).Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["anime_name"]; ok {
+		t.Error("anime_name sent with a list type other than filter")
+	}
+}
+
+func TestAnimeQueryValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *AnimeQuery
+		want  []string
+	}{
+		{"filters need the filter list", NewAnimeQuery().ListType(TopTv).Season(Fall), []string{"filters can only be used"}},
+		{"reversed years", NewAnimeQuery().Years(2022, 2020), []string{"must not be after"}},
+		{"negative year", NewAnimeQuery().Year(-1), []string{"year must be positive"}},
+		{"unknown type", NewAnimeQuery().Type("Manga"), []string{"invalid anime type"}},
+		{"unknown status", NewAnimeQuery().Status("Paused"), []string{"invalid status"}},
+		{"genre id", NewAnimeQuery().Genres(0), []string{"genre id must be positive"}},
+		{"studio id", NewAnimeQuery().Studios(-2), []string{"studio id must be positive"}},
+		{"zero limit", NewAnimeQuery().Limit(0), []string{"negative limit or zero"}},
+		{"every error", NewAnimeQuery().Order("best").Offset(-1).Type("Manga"), []string{"invalid order", "negative Offset", "invalid anime type"}},
+	}
+	for _, tt := range tests {
+		err := tt.query.Validate()
+		if err == nil {
+			t.Errorf("%s: Validate() = nil", tt.name)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: error %q does not mention %q", tt.name, err, want)
+			}
+		}
+		if s := tt.query.String(); !strings.HasPrefix(s, "invalid query: ") {
+			t.Errorf("%s: String() = %q", tt.name, s)
+		}
+	}
+}
+
+func TestAnimeQueryString(t *testing.T) {
+	s := NewAnimeQuery().Type(Movie).String()
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &payload); err != nil {
+		t.Fatalf("String() = %q is not JSON: %v", s, err)
+	}
+	if payload["anime_type"] != "Movie" {
+		t.Errorf("anime_type = %v, want Movie", payload["anime_type"])
+	}
+}
+
+func TestAnimeQuerySendsPayload(t *testing.T) {
+	var sent JsonPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.Unmarshal([]byte(r.URL.Query().Get("json")), &sent)
+		_, _ = w.Write([]byte(`{"response":{"data":[{"anime_id":"1"}]}}`))
+	}))
+	defer srv.Close()
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL))
+
+	animes, err := c.AnimeService.QueryWithContext(context.Background(), NewAnimeQuery().Season(Winter).Year(2020))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(animes) != 1 {
+		t.Fatalf("got %d animes, want 1", len(animes))
+	}
+	if sent["anime_season"] != "Winter" || sent["anime_release_years"] != float64(2020) {
+		t.Errorf("sent payload %v", sent)
+	}
+
+	if _, err := c.AnimeService.Query(NewAnimeQuery().Year(-1)); err == nil {
+		t.Error("invalid query sent")
+	}
+}
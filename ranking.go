@@ -0,0 +1,163 @@
+package tohru
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LinkRanking orders download links best first, Compare returns a negative
+// number when a is better than b. Ties keep the API order.
+type LinkRanking interface {
+	Compare(a, b DownloadInfo) int
+}
+
+// LinkRankingFunc adapts a function to LinkRanking.
+type LinkRankingFunc func(a, b DownloadInfo) int
+
+func (f LinkRankingFunc) Compare(a, b DownloadInfo) int {
+	return f(a, b)
+}
+
+// WithLinkRanking sets the order of the links returned by GetDirectDownloadInfos,
+// links keep the API order by default.
+func WithLinkRanking(r LinkRanking) Option {
+	return func(t *TohruClient) {
+		t.ranking = ranking{r}
+	}
+}
+
+// WithHostPreference ranks links of hosts first, in the given order,
+// then by quality.
+func WithHostPreference(hosts ...string) Option {
+	return WithLinkRanking(ChainRanking(ByHostPreference(hosts...), ByQuality()))
+}
+
+// ByHostPreference ranks links of hosts first, in the given order.
+// A host matches its subdomains.
+func ByHostPreference(hosts ...string) LinkRanking {
+	rank := func(host string) int {
+		host = strings.ToLower(host)
+		for i, h := range hosts {
+			h = strings.ToLower(h)
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return i
+			}
+		}
+		return len(hosts)
+	}
+	return LinkRankingFunc(func(a, b DownloadInfo) int {
+		return cmp.Compare(rank(a.Host), rank(b.Host))
+	})
+}
+
+// ByQuality ranks links with the highest resolution label first.
+func ByQuality() LinkRanking {
+	return LinkRankingFunc(func(a, b DownloadInfo) int {
+		return cmp.Compare(quality(b.Label), quality(a.Label))
+	})
+}
+
+type resolveTimeRanking struct{}
+
+func (resolveTimeRanking) Compare(a, b DownloadInfo) int {
+	return cmp.Compare(a.ResolveDuration, b.ResolveDuration)
+}
+
+// ByResolveTime ranks the fastest resolved links first. Used alone or first
+// in a ChainRanking, GetDirectDownloadInfosWithMax stops resolving as soon
+// as enough links are resolved.
+func ByResolveTime() LinkRanking {
+	return resolveTimeRanking{}
+}
+
+type chainRanking []LinkRanking
+
+func (c chainRanking) Compare(a, b DownloadInfo) int {
+	for _, r := range c {
+		if cmp := r.Compare(a, b); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// ChainRanking compares with each ranking in turn until one is not a tie.
+func ChainRanking(rankings ...LinkRanking) LinkRanking {
+	return chainRanking(rankings)
+}
+
+// stopMode tells resolveLinks when it has enough links for a ranking.
+type stopMode int
+
+const (
+	// stopAll waits for every link, the best one may be the last to resolve.
+	stopAll stopMode = iota
+	// stopInOrder stops once max links are resolved and every link
+	// before them in the API order has settled.
+	stopInOrder
+	// stopFirstResolved stops once max links are resolved.
+	stopFirstResolved
+)
+
+type ranking struct {
+	LinkRanking
+}
+
+func (r ranking) stopMode() stopMode {
+	switch leadingRanking(r.LinkRanking).(type) {
+	case nil:
+		return stopInOrder
+	case resolveTimeRanking:
+		return stopFirstResolved
+	default:
+		return stopAll
+	}
+}
+
+// leadingRanking returns the ranking deciding first, nil when every link ties.
+func leadingRanking(r LinkRanking) LinkRanking {
+	for {
+		chain, ok := r.(chainRanking)
+		if !ok {
+			return r
+		}
+		if len(chain) == 0 {
+			return nil
+		}
+		r = chain[0]
+	}
+}
+
+func (r ranking) sort(infos DownloadInfos) {
+	slices.SortStableFunc(infos, func(a, b DownloadInfo) int {
+		if r.LinkRanking != nil {
+			if c := r.Compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.Index, b.Index)
+	})
+}
+
+var qualityPattern = regexp.MustCompile(`(?i)(\d{3,4})p`)
+
+// qualityLabel extracts a resolution label like 1080p from s.
+func qualityLabel(s string) string {
+	m := qualityPattern.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	return m[1] + "p"
+}
+
+func quality(label string) int {
+	m := qualityPattern.FindStringSubmatch(label)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
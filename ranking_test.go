@@ -0,0 +1,37 @@
+package tohru
+
+import "testing"
+
+func TestRankingStopMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		ranking LinkRanking
+		want    stopMode
+	}{
+		{"api order", nil, stopInOrder},
+		{"empty chain", ChainRanking(), stopInOrder},
+		{"resolve time", ByResolveTime(), stopFirstResolved},
+		{"chained resolve time", ChainRanking(ByResolveTime(), ByQuality()), stopFirstResolved},
+		{"nested resolve time", ChainRanking(ChainRanking(ByResolveTime()), ByQuality()), stopFirstResolved},
+		{"resolve time tie breaker", ChainRanking(ByQuality(), ByResolveTime()), stopAll},
+		{"quality", ByQuality(), stopAll},
+	}
+	for _, tt := range tests {
+		if got := (ranking{tt.ranking}).stopMode(); got != tt.want {
+			t.Errorf("%s: stopMode() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStopInOrderWaitsForEarlierLinks(t *testing.T) {
+	done := stopInOrder.tracker(4, 1)
+	if done(2, true) {
+		t.Fatal("stopped before links 0 and 1 settled")
+	}
+	if done(0, false) {
+		t.Fatal("stopped before link 1 settled")
+	}
+	if !done(1, false) {
+		t.Fatal("did not stop once every link before link 2 settled")
+	}
+}
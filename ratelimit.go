@@ -0,0 +1,139 @@
+package tohru
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiter, it is safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	waits     int64
+	waiting   int
+	totalWait time.Duration
+}
+
+// RateLimitStats is a snapshot of a RateLimiter usage.
+type RateLimitStats struct {
+	// Waits is the number of calls that had to block.
+	Waits int64
+	// Waiting is the number of calls currently blocked.
+	Waiting   int
+	TotalWait time.Duration
+	Tokens    float64
+}
+
+// NewRateLimiter returns a limiter allowing rps events per second
+// with bursts of up to burst events.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// WaitN blocks until n tokens are available or ctx is done.
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || l.rate <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.waits++
+	l.waiting++
+	l.mu.Unlock()
+
+	start := time.Now()
+	err := sleep(ctx, wait)
+
+	l.mu.Lock()
+	l.waiting--
+	l.totalWait += time.Since(start)
+	if err != nil {
+		// give back the reserved tokens
+		l.tokens += float64(n)
+	}
+	l.mu.Unlock()
+	return err
+}
+
+func (l *RateLimiter) Stats() RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return RateLimitStats{
+		Waits:     l.waits,
+		Waiting:   l.waiting,
+		TotalWait: l.totalWait,
+		Tokens:    l.tokens,
+	}
+}
+
+// WithRateLimit limits every request made by the client.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(t *TohruClient) {
+		t.limiter = NewRateLimiter(rps, burst)
+	}
+}
+
+// WithEndpointRateLimit limits requests sent to path, e.g. PublishedAnimesPath,
+// on top of the global limit.
+func WithEndpointRateLimit(path string, rps float64, burst int) Option {
+	return func(t *TohruClient) {
+		if t.endpointLimiters == nil {
+			t.endpointLimiters = make(map[string]*RateLimiter)
+		}
+		t.endpointLimiters[limiterKey(path)] = NewRateLimiter(rps, burst)
+	}
+}
+
+// RateLimitStats returns the stats of the limiter for path,
+// an empty path returns the global limiter stats.
+func (c *TohruClient) RateLimitStats(path string) (RateLimitStats, bool) {
+	l := c.limiter
+	if path != "" {
+		l = c.endpointLimiters[limiterKey(path)]
+	}
+	if l == nil {
+		return RateLimitStats{}, false
+	}
+	return l.Stats(), true
+}
+
+func (c *TohruClient) waitRateLimit(ctx context.Context, path string) error {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return c.endpointLimiters[limiterKey(path)].Wait(ctx)
+}
+
+func limiterKey(path string) string {
+	return strings.TrimPrefix(path, "/")
+}
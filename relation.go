@@ -0,0 +1,84 @@
+package tohru
+
+import (
+	"context"
+	"strconv"
+)
+
+// RelationGraph links an anime to its related animes (sequels, prequels, side stories).
+// The API does not label the kind of relation.
+type RelationGraph struct {
+	Root int
+	// Nodes holds every anime reached, keyed by ID.
+	Nodes map[int]Anime
+	// Edges maps an anime ID to the IDs of its related animes,
+	// only animes closer than the requested depth have edges.
+	Edges map[int][]int
+	// Depth is the distance of every node from Root.
+	Depth map[int]int
+}
+
+// RelationGraph walks related animes of animeID breadth first up to depth hops.
+func (s *AnimeService) RelationGraph(animeID, depth int) (*RelationGraph, error) {
+	return s.RelationGraphWithContext(context.Background(), animeID, depth)
+}
+
+func (s *AnimeService) RelationGraphWithContext(ctx context.Context, animeID, depth int) (*RelationGraph, error) {
+	g := &RelationGraph{
+		Root:  animeID,
+		Nodes: make(map[int]Anime),
+		Edges: make(map[int][]int),
+		Depth: map[int]int{animeID: 0},
+	}
+
+	if depth < 1 {
+		depth = 1
+	}
+
+	queue := []int{animeID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		details, err := s.GetAnimeDetailsWithContext(ctx, id)
+		if err != nil {
+			return g, err
+		}
+		if _, ok := g.Nodes[id]; !ok {
+			g.Nodes[id] = details.anime()
+		}
+
+		for _, related := range details.RelatedAnimes.Animes {
+			relatedID, err := strconv.Atoi(related.AnimeID)
+			if err != nil || relatedID == id {
+				continue
+			}
+			g.Edges[id] = append(g.Edges[id], relatedID)
+			if _, seen := g.Depth[relatedID]; seen {
+				continue
+			}
+			g.Nodes[relatedID] = related
+			g.Depth[relatedID] = g.Depth[id] + 1
+			if g.Depth[relatedID] < depth {
+				queue = append(queue, relatedID)
+			}
+		}
+	}
+	return g, nil
+}
+
+func (ad AnimeDetails) anime() Anime {
+	return Anime{
+		AnimeID:            ad.AnimeID,
+		AnimeName:          ad.AnimeName,
+		AnimeType:          ad.AnimeType,
+		AnimeStatus:        ad.AnimeStatus,
+		AnimeSeason:        ad.AnimeSeason,
+		AnimeReleaseYear:   ad.AnimeReleaseYear,
+		AnimeRating:        ad.AnimeRating,
+		AnimeGenres:        ad.AnimeGenres,
+		AnimeCoverImageURL: ad.AnimeCoverImageURL,
+		AnimeTrailerURL:    ad.AnimeTrailerURL,
+		AnimeReleaseDay:    ad.AnimeReleaseDay,
+	}
+}
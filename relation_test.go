@@ -0,0 +1,112 @@
+package tohru
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// relationServer answers anime details with the related animes of graph
+// and records the fetched ids.
+type relationServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	fetched []int
+}
+
+func newRelationServer(t *testing.T, graph map[int][]int) *relationServer {
+	t.Helper()
+	rs := &relationServer{}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.URL.Query().Get("anime_id"))
+		rs.mu.Lock()
+		rs.fetched = append(rs.fetched, id)
+		rs.mu.Unlock()
+
+		related := []Anime{}
+		for _, rel := range graph[id] {
+			related = append(related, Anime{AnimeID: strconv.Itoa(rel), AnimeName: "anime " + strconv.Itoa(rel)})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"response": map[string]interface{}{
+				"anime_id":       strconv.Itoa(id),
+				"anime_name":     "anime " + strconv.Itoa(id),
+				"related_animes": map[string]interface{}{"data": related},
+			},
+		})
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func TestRelationGraphDepth(t *testing.T) {
+	// 1 -> 2 -> 3 -> 4 with 3 pointing back to 1 and 2 to itself
+	graph := map[int][]int{1: {2}, 2: {2, 3}, 3: {4, 1}, 4: {}}
+	tests := []struct {
+		depth   int
+		nodes   []int
+		fetched []int
+		edges   map[int][]int
+	}{
+		{0, []int{1, 2}, []int{1}, map[int][]int{1: {2}}},
+		{1, []int{1, 2}, []int{1}, map[int][]int{1: {2}}},
+		{2, []int{1, 2, 3}, []int{1, 2}, map[int][]int{1: {2}, 2: {3}}},
+		{10, []int{1, 2, 3, 4}, []int{1, 2, 3, 4}, map[int][]int{1: {2}, 2: {3}, 3: {4, 1}}},
+	}
+	for _, tt := range tests {
+		rs := newRelationServer(t, graph)
+		s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(rs.URL)).AnimeService
+
+		g, err := s.RelationGraph(1, tt.depth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var nodes []int
+		for id := range g.Nodes {
+			nodes = append(nodes, id)
+		}
+		slices.Sort(nodes)
+		if !slices.Equal(nodes, tt.nodes) {
+			t.Errorf("depth %d: nodes %v, want %v", tt.depth, nodes, tt.nodes)
+		}
+		if !slices.Equal(rs.fetched, tt.fetched) {
+			t.Errorf("depth %d: fetched %v, want %v", tt.depth, rs.fetched, tt.fetched)
+		}
+		if len(g.Edges) != len(tt.edges) {
+			t.Errorf("depth %d: edges %v, want %v", tt.depth, g.Edges, tt.edges)
+		}
+		for id, want := range tt.edges {
+			if !slices.Equal(g.Edges[id], want) {
+				t.Errorf("depth %d: edges of %d = %v, want %v", tt.depth, id, g.Edges[id], want)
+			}
+		}
+		for id, d := range g.Depth {
+			if want := slices.Index([]int{1, 2, 3, 4}, id); d != want {
+				t.Errorf("depth %d: anime %d at depth %d, want %d", tt.depth, id, d, want)
+			}
+		}
+		if g.Nodes[1].AnimeName != "anime 1" {
+			t.Errorf("depth %d: root node %+v", tt.depth, g.Nodes[1])
+		}
+	}
+}
+
+func TestRelationGraphError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	s := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL)).AnimeService
+
+	g, err := s.RelationGraph(1, 2)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if g == nil || g.Root != 1 {
+		t.Errorf("got graph %+v, want the partial graph", g)
+	}
+}
@@ -0,0 +1,144 @@
+package tohru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/khatibomar/kobayashi"
+)
+
+var ErrNoResolver = errors.New("no resolver for host")
+
+// LinkResolver turns a file host page link into a direct download link.
+type LinkResolver interface {
+	CanResolve(host string) bool
+	Resolve(ctx context.Context, link string) (string, error)
+}
+
+// KobayashiResolver adapts kobayashi.Decoder to LinkResolver.
+type KobayashiResolver struct {
+	decoder kobayashi.Decoder
+}
+
+var kobayashiHosts = []string{"mediafire", "drive.google", "mixdrop", "fembed", "ok.ru"}
+
+func (k *KobayashiResolver) CanResolve(host string) bool {
+	for _, h := range kobayashiHosts {
+		if strings.Contains(host, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns as soon as ctx is done, kobayashi has no cancellation
+// so the decode itself keeps running in the background until it returns.
+func (k *KobayashiResolver) Resolve(ctx context.Context, link string) (string, error) {
+	type result struct {
+		url string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		url, err := k.decoder.Decode(link)
+		done <- result{url, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-done:
+		return r.url, r.err
+	}
+}
+
+// ResolverRegistry picks the first enabled resolver able to resolve a host,
+// it is safe for concurrent use.
+type ResolverRegistry struct {
+	mu        sync.RWMutex
+	resolvers []LinkResolver
+	disabled  map[string]bool
+}
+
+// NewResolverRegistry returns a registry trying resolvers in order.
+func NewResolverRegistry(resolvers ...LinkResolver) *ResolverRegistry {
+	return &ResolverRegistry{
+		resolvers: resolvers,
+		disabled:  make(map[string]bool),
+	}
+}
+
+// Register adds r after the already registered resolvers.
+func (r *ResolverRegistry) Register(res LinkResolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolvers = append(r.resolvers, res)
+}
+
+// RegisterFirst adds r before the already registered resolvers.
+func (r *ResolverRegistry) RegisterFirst(res LinkResolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolvers = append([]LinkResolver{res}, r.resolvers...)
+}
+
+// Disable stops resolving links of host and its subdomains.
+func (r *ResolverRegistry) Disable(host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disabled[strings.ToLower(host)] = true
+}
+
+func (r *ResolverRegistry) Enable(host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.disabled, strings.ToLower(host))
+}
+
+func (r *ResolverRegistry) isDisabled(host string) bool {
+	host = strings.ToLower(host)
+	for h := range r.disabled {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolver returns the resolver used for host.
+func (r *ResolverRegistry) Resolver(host string) (LinkResolver, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.isDisabled(host) {
+		return nil, false
+	}
+	for _, res := range r.resolvers {
+		if res.CanResolve(host) {
+			return res, true
+		}
+	}
+	return nil, false
+}
+
+func (r *ResolverRegistry) Resolve(ctx context.Context, link string) (string, error) {
+	host := linkHost(link)
+	res, ok := r.Resolver(host)
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrNoResolver, host)
+	}
+	return res.Resolve(ctx, link)
+}
+
+// WithLinkResolvers replaces the default kobayashi resolver.
+func WithLinkResolvers(resolvers ...LinkResolver) Option {
+	return func(t *TohruClient) {
+		t.resolvers = NewResolverRegistry(resolvers...)
+	}
+}
+
+// Resolvers returns the registry used to resolve download links.
+func (c *TohruClient) Resolvers() *ResolverRegistry {
+	return c.resolvers
+}
@@ -0,0 +1,95 @@
+package tohru
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// hostResolver resolves the links of hosts containing host to name.
+type hostResolver struct {
+	host string
+	name string
+}
+
+func (r hostResolver) CanResolve(host string) bool { return strings.Contains(host, r.host) }
+
+func (r hostResolver) Resolve(_ context.Context, link string) (string, error) {
+	return r.name + ":" + link, nil
+}
+
+func TestResolverRegistryOrder(t *testing.T) {
+	reg := NewResolverRegistry(hostResolver{"example", "first"})
+	reg.Register(hostResolver{"example", "second"})
+	reg.Register(hostResolver{"other", "other"})
+
+	got, err := reg.Resolve(context.Background(), "https://files.example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "first:") {
+		t.Errorf("Register put the resolver first, got %q", got)
+	}
+
+	reg.RegisterFirst(hostResolver{"example", "preferred"})
+	got, err = reg.Resolve(context.Background(), "https://files.example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "preferred:") {
+		t.Errorf("RegisterFirst did not put the resolver first, got %q", got)
+	}
+
+	if res, ok := reg.Resolver("cdn.other.net"); !ok || res.(hostResolver).name != "other" {
+		t.Errorf("Resolver(cdn.other.net) = %v, %v", res, ok)
+	}
+}
+
+func TestResolverRegistryDisable(t *testing.T) {
+	reg := NewResolverRegistry(hostResolver{"example", "example"})
+
+	reg.Disable("Example.com")
+	for _, host := range []string{"example.com", "www.example.com", "a.b.example.com"} {
+		if _, ok := reg.Resolver(host); ok {
+			t.Errorf("%s resolved after disabling example.com", host)
+		}
+	}
+	if _, ok := reg.Resolver("notexample.com"); !ok {
+		t.Error("disabling example.com disabled notexample.com")
+	}
+	if _, err := reg.Resolve(context.Background(), "https://www.example.com/a"); !errors.Is(err, ErrNoResolver) {
+		t.Errorf("got error %v, want ErrNoResolver", err)
+	}
+
+	reg.Enable("example.com")
+	if _, ok := reg.Resolver("www.example.com"); !ok {
+		t.Error("www.example.com still disabled after Enable")
+	}
+}
+
+func TestResolverRegistryNoResolver(t *testing.T) {
+	reg := NewResolverRegistry(hostResolver{"example", "example"})
+	_, err := reg.Resolve(context.Background(), "https://unknown.org/file")
+	if !errors.Is(err, ErrNoResolver) {
+		t.Fatalf("got error %v, want ErrNoResolver", err)
+	}
+	if !strings.Contains(err.Error(), `"unknown.org"`) {
+		t.Errorf("error %q does not name the host", err)
+	}
+}
+
+func TestWithLinkResolvers(t *testing.T) {
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithLinkResolvers(hostResolver{"example", "custom"}))
+	if _, ok := c.Resolvers().Resolver("mediafire.com"); ok {
+		t.Error("the default kobayashi resolver was kept")
+	}
+	if _, ok := c.Resolvers().Resolver("example.com"); !ok {
+		t.Error("the custom resolver was not registered")
+	}
+
+	c = NewTohruClient(NewConfig("id", "secret", ""))
+	if _, ok := c.Resolvers().Resolver("www.mediafire.com"); !ok {
+		t.Error("no default resolver for mediafire")
+	}
+}
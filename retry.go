@@ -0,0 +1,124 @@
+package tohru
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how TohruClient retries failed requests.
+// The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	// RetryNonIdempotent allows retrying POST requests, Anslayer uses
+	// them for read only endpoints like get-episodes-new.
+	RetryNonIdempotent bool
+	// OnAttempt is called after every failed attempt.
+	OnAttempt func(RetryAttempt)
+}
+
+// RetryAttempt describes a failed attempt passed to RetryPolicy.OnAttempt.
+type RetryAttempt struct {
+	Attempt    int
+	Method     string
+	Path       string
+	StatusCode int
+	Err        error
+	// Wait is the delay before the next attempt, zero when giving up.
+	Wait  time.Duration
+	Retry bool
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(t *TohruClient) {
+		t.retry = p
+	}
+}
+
+func (p RetryPolicy) shouldRetry(attempt int, method string, res *http.Response, err error) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	if !p.RetryNonIdempotent && !idempotent(method) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if res == nil {
+		return err != nil
+	}
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
+
+// backoff returns the delay before the next attempt, a Retry-After header
+// is followed but capped by MaxBackoff.
+func (p RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	minBackoff, maxBackoff := p.MinBackoff, p.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = DefaultRetryPolicy.MinBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	if res != nil {
+		if wait, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+			return min(wait, maxBackoff)
+		}
+	}
+	wait := minBackoff << (attempt - 1)
+	if wait <= 0 || wait > maxBackoff {
+		wait = maxBackoff
+	}
+	// equal jitter, between half and the whole backoff
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
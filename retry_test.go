@@ -0,0 +1,146 @@
+package tohru
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with status then answers
+// every request with an anime.
+func flakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":{"anime_id":"1","data":[]}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+var fastRetry = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+func TestRetryStatuses(t *testing.T) {
+	tests := []struct {
+		status int
+		calls  int32
+		err    error
+	}{
+		{http.StatusTooManyRequests, 2, nil},
+		{http.StatusServiceUnavailable, 2, nil},
+		{http.StatusNotFound, 1, ErrNotFound},
+	}
+	for _, tt := range tests {
+		srv, calls := flakyServer(t, 1, tt.status, nil)
+		c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL), WithRetryPolicy(fastRetry))
+		_, err := c.AnimeService.GetAnimeDetails(1)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("status %d: got error %v, want %v", tt.status, err, tt.err)
+		}
+		if got := calls.Load(); got != tt.calls {
+			t.Errorf("status %d: %d calls, want %d", tt.status, got, tt.calls)
+		}
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	srv, calls := flakyServer(t, 10, http.StatusBadGateway, nil)
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL), WithRetryPolicy(fastRetry))
+	if _, err := c.AnimeService.GetAnimeDetails(1); !errors.Is(err, ErrServer) {
+		t.Fatalf("got error %v, want ErrServer", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("%d calls, want 3", got)
+	}
+}
+
+func TestRetrySkipsPost(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL), WithRetryPolicy(fastRetry))
+	if _, err := c.EpisodeService.GetEpisodesList(1); !errors.Is(err, ErrServer) {
+		t.Fatalf("got error %v, want ErrServer", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("POST sent %d times, want once", got)
+	}
+
+	policy := fastRetry
+	policy.RetryNonIdempotent = true
+	srv, calls = flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+	c = NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL), WithRetryPolicy(policy))
+	if _, err := c.EpisodeService.GetEpisodesList(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("POST sent %d times with RetryNonIdempotent, want twice", got)
+	}
+}
+
+func TestRetryAfterCappedByMaxBackoff(t *testing.T) {
+	srv, _ := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"86400"}})
+
+	var mu sync.Mutex
+	var attempts []RetryAttempt
+	policy := fastRetry
+	policy.OnAttempt = func(a RetryAttempt) {
+		mu.Lock()
+		attempts = append(attempts, a)
+		mu.Unlock()
+	}
+	c := NewTohruClient(NewConfig("id", "secret", ""), WithBaseURL(srv.URL), WithRetryPolicy(policy))
+
+	start := time.Now()
+	if _, err := c.AnimeService.GetAnimeDetails(1); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("waited %v despite MaxBackoff", d)
+	}
+	if len(attempts) != 1 {
+		t.Fatalf("OnAttempt called %d times, want once", len(attempts))
+	}
+	a := attempts[0]
+	if a.Attempt != 1 || !a.Retry || a.StatusCode != http.StatusTooManyRequests || a.Method != http.MethodGet {
+		t.Errorf("unexpected attempt %+v", a)
+	}
+	if a.Wait != policy.MaxBackoff {
+		t.Errorf("waited %v, want MaxBackoff %v", a.Wait, policy.MaxBackoff)
+	}
+	if !errors.Is(a.Err, ErrRateLimited) {
+		t.Errorf("attempt error %v, want ErrRateLimited", a.Err)
+	}
+}
+
+func TestRetryAfterFollowed(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Minute}
+	res := &http.Response{Header: http.Header{"Retry-After": {"3"}}}
+	if got := policy.backoff(1, res); got != 3*time.Second {
+		t.Errorf("got %v, want 3s", got)
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, base := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: time.Second} {
+		for i := 0; i < 50; i++ {
+			if got := policy.backoff(attempt, nil); got < base/2 || got > base {
+				t.Fatalf("attempt %d: backoff %v outside [%v, %v]", attempt, got, base/2, base)
+			}
+		}
+	}
+}
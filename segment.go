@@ -0,0 +1,220 @@
+package tohru
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// downloadState is persisted next to the part file so a segmented
+// download can resume after a crash.
+type downloadState struct {
+	Size     int64          `json:"size"`
+	Segments []segmentState `json:"segments"`
+}
+
+type segmentState struct {
+	Start   int64 `json:"start"`
+	End     int64 `json:"end"`
+	Written int64 `json:"written"`
+}
+
+func (s segmentState) done() bool {
+	return s.Start+s.Written > s.End
+}
+
+func newDownloadState(size int64, n int) *downloadState {
+	if int64(n) > size {
+		n = int(max(size, 1))
+	}
+	st := &downloadState{Size: size}
+	chunk := size / int64(n)
+	for i := 0; i < n; i++ {
+		start := int64(i) * chunk
+		end := start + chunk - 1
+		if i == n-1 {
+			end = size - 1
+		}
+		st.Segments = append(st.Segments, segmentState{Start: start, End: end})
+	}
+	return st
+}
+
+// loadDownloadState reads the state at path, it is only trusted when the
+// part file it describes exists with the expected size.
+func loadDownloadState(path, part string, size int64) (*downloadState, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var st downloadState
+	if err := json.Unmarshal(data, &st); err != nil || st.Size != size {
+		return nil, false
+	}
+	if fi, err := os.Stat(part); err != nil || fi.Size() != st.Size {
+		return nil, false
+	}
+	return &st, true
+}
+
+func (st *downloadState) save(path string) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return writeState(path, data)
+}
+
+// writeState replaces the state file at path with data.
+func writeState(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (st *downloadState) written() int64 {
+	var n int64
+	for _, seg := range st.Segments {
+		n += seg.Written
+	}
+	return n
+}
+
+// fetchSegments downloads info to part with d.Segments parallel ranged requests,
+// the state file records the progress of every segment.
+func (d *Downloader) fetchSegments(ctx context.Context, info DownloadInfo, part, statePath string, size int64) error {
+	st, ok := loadDownloadState(statePath, part, size)
+	if !ok {
+		st = newDownloadState(size, d.Segments)
+	}
+
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if !ok {
+		// a part file left by a single stream download cannot be trusted
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+	}
+	if err := f.Truncate(size); err != nil {
+		return err
+	}
+	if err := st.save(statePath); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		saveMu   sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		lastSave time.Time
+	)
+	report := d.throttledProgress()
+	// update records n bytes written by segment i and persists the state
+	// at most every second. The state is written and the progress reported
+	// outside mu so the other segments are not blocked meanwhile.
+	update := func(i int, n int64) {
+		mu.Lock()
+		st.Segments[i].Written += n
+		var data []byte
+		if time.Since(lastSave) >= time.Second {
+			lastSave = time.Now()
+			data, _ = json.Marshal(st)
+		}
+		p := Progress{Link: info, Downloaded: st.written(), Total: size}
+		mu.Unlock()
+
+		if data != nil {
+			saveMu.Lock()
+			_ = writeState(statePath, data)
+			saveMu.Unlock()
+		}
+		report(p)
+	}
+
+	for i := range st.Segments {
+		if st.Segments[i].done() {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mu.Lock()
+			seg := st.Segments[i]
+			mu.Unlock()
+			if err := d.fetchSegment(ctx, info, f, seg, func(n int64) { update(i, n) }); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("segment %d: %w", i, err)
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := st.save(statePath); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	d.progress(Progress{Link: info, Downloaded: size, Total: size, Done: true})
+	return nil
+}
+
+func (d *Downloader) fetchSegment(ctx context.Context, info DownloadInfo, f *os.File, seg segmentState, written func(int64)) error {
+	start := seg.Start + seg.Written
+	res, err := d.get(ctx, info.EpisodeDirectDownloadLink, "bytes="+strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(seg.End, 10))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusPartialContent {
+		return statusError(res.StatusCode)
+	}
+
+	w := io.NewOffsetWriter(f, start)
+	buf := make([]byte, 32<<10)
+	remaining := seg.End - start + 1
+	for remaining > 0 {
+		n, err := d.read(ctx, res.Body, buf[:min(int64(len(buf)), remaining)])
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			remaining -= int64(n)
+			written(int64(n))
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if remaining > 0 {
+		return fmt.Errorf("short transfer, %d bytes missing", remaining)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package tohru
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newRangeServer serves content with range support and records the Range
+// header of every GET.
+func newRangeServer(t *testing.T, content []byte) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+		}
+		http.ServeContent(w, r, "episode.mp4", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(ranges)
+	}
+}
+
+func newSegmentedDownloader(segments int) *Downloader {
+	d := NewTohruClient(NewConfig("id", "secret", "")).EpisodeService.NewDownloader()
+	d.Segments = segments
+	return d
+}
+
+func checkDownloaded(t *testing.T, dest string, want []byte) {
+	t.Helper()
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, leftover := range []string{dest + ".part", dest + ".state"} {
+		if _, err := os.Stat(leftover); err == nil {
+			t.Errorf("%s left behind", filepath.Base(leftover))
+		}
+	}
+}
+
+func TestSegmentedDownloadIgnoresStateWithoutPartFile(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10)
+	srv, _ := newRangeServer(t, content)
+
+	dest := filepath.Join(t.TempDir(), "episode.mp4")
+	st := newDownloadState(int64(len(content)), 2)
+	st.Segments[0].Written = 50
+	if err := st.save(dest + ".state"); err != nil {
+		t.Fatal(err)
+	}
+
+	info := DownloadInfo{EpisodeDirectDownloadLink: srv.URL + "/episode.mp4", Host: "127.0.0.1"}
+	if err := newSegmentedDownloader(2).Download(context.Background(), info, dest); err != nil {
+		t.Fatal(err)
+	}
+	checkDownloaded(t, dest, content)
+}
+
+func TestSegmentedDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+	srv, ranges := newRangeServer(t, content)
+
+	dest := filepath.Join(t.TempDir(), "episode.mp4")
+	info := DownloadInfo{EpisodeDirectDownloadLink: srv.URL + "/episode.mp4", Host: "127.0.0.1"}
+	if err := newSegmentedDownloader(4).Download(context.Background(), info, dest); err != nil {
+		t.Fatal(err)
+	}
+	checkDownloaded(t, dest, content)
+
+	got := ranges()
+	slices.Sort(got)
+	want := []string{"bytes=0-249", "bytes=250-499", "bytes=500-749", "bytes=750-999"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got ranges %q, want %q", got, want)
+	}
+}
+
+func TestSegmentedDownloadResumesFromState(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10)
+	srv, ranges := newRangeServer(t, content)
+
+	dest := filepath.Join(t.TempDir(), "episode.mp4")
+	st := newDownloadState(int64(len(content)), 2)
+	st.Segments[0].Written = 50
+	if err := st.save(dest + ".state"); err != nil {
+		t.Fatal(err)
+	}
+	part := append(slices.Clone(content[:50]), make([]byte, 50)...)
+	if err := os.WriteFile(dest+".part", part, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := DownloadInfo{EpisodeDirectDownloadLink: srv.URL + "/episode.mp4", Host: "127.0.0.1"}
+	if err := newSegmentedDownloader(2).Download(context.Background(), info, dest); err != nil {
+		t.Fatal(err)
+	}
+	checkDownloaded(t, dest, content)
+	if got, want := ranges(), []string{"bytes=50-99"}; !slices.Equal(got, want) {
+		t.Errorf("got ranges %q, want %q", got, want)
+	}
+}
+
+func TestBandwidthLimitIsShared(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 96<<10)
+	srv, _ := newRangeServer(t, content)
+
+	// 192KiB against a 64KiB burst and 128KiB/s take a second when the
+	// limit is shared, a quarter of it when every download has its own
+	d := newSegmentedDownloader(2)
+	d.BandwidthLimit = 128 << 10
+	dir := t.TempDir()
+	info := DownloadInfo{EpisodeDirectDownloadLink: srv.URL + "/episode.mp4", Host: "127.0.0.1"}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, name := range []string{"a.mp4", "b.mp4"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.Download(context.Background(), info, filepath.Join(dir, name)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 700*time.Millisecond {
+		t.Errorf("downloads took %v, want at least 700ms", elapsed)
+	}
+	for _, name := range []string{"a.mp4", "b.mp4"} {
+		checkDownloaded(t, filepath.Join(dir, name), content)
+	}
+}
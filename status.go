@@ -0,0 +1,20 @@
+package tohru
+
+import "fmt"
+
+const (
+	CurrentlyAiringStatus status = "Currently Airing"
+	FinishedAiring        status = "Finished Airing"
+	NotYetAired           status = "Not Yet Aired"
+)
+
+type status string
+
+func (s status) valid() error {
+	switch s {
+	case CurrentlyAiringStatus, FinishedAiring, NotYetAired:
+		return nil
+	default:
+		return fmt.Errorf("invalid status, Please use predefined statuses by package")
+	}
+}